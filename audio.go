@@ -0,0 +1,166 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// defaultTranscriptionModel is the model used by Transcribe when none is set.
+const defaultTranscriptionModel = "whisper-large-v3"
+
+// TranscriptionRequest represents a request to transcribe an audio file.
+type TranscriptionRequest struct {
+	// File is the audio to transcribe. It is streamed to the API, not read into memory.
+	File io.Reader
+	// FileName is the name of the audio file, e.g. "meeting.mp3". Its extension determines
+	// the content type of the file part and tells the API the format of the audio.
+	FileName string
+	// Model specifies the model to use. It defaults to whisper-large-v3.
+	Model string
+	// Language is the ISO-639-1 code of the language of the audio, e.g. "en". It is optional
+	// but improves accuracy and latency.
+	Language string
+	// Prompt is an optional text to guide the style of the transcription or continue a
+	// previous segment.
+	Prompt string
+	// ResponseFormat is the format of the transcription: "json" (the default), "verbose_json"
+	// or "text".
+	ResponseFormat string
+}
+
+// Transcribe transcribes an audio file and returns its text. Since the file is streamed,
+// the request is not retried.
+func (c *Client) Transcribe(ctx context.Context, req TranscriptionRequest) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	if req.File == nil {
+		return "", errors.New("groq: transcription file is required")
+	}
+	if req.FileName == "" {
+		return "", errors.New("groq: transcription file name is required")
+	}
+	if req.Model == "" {
+		req.Model = defaultTranscriptionModel
+	}
+
+	if !c.inflight.begin() {
+		return "", ErrShuttingDown
+	}
+	defer c.inflight.end()
+
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeTranscriptionForm(form, req))
+	}()
+	// The writer fails once the request is done, in case it didn't read the whole body.
+	defer body.Close()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/transcriptions", body)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	c.setHeaders(httpReq.Header)
+
+	resp, err := c.doRequest(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	if req.ResponseFormat != "" && req.ResponseFormat != "json" && req.ResponseFormat != "verbose_json" {
+		text, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	var transcription struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&transcription); err != nil {
+		return "", err
+	}
+	return transcription.Text, nil
+}
+
+// audioContentTypes maps the extensions of the audio formats accepted by the API to their
+// content types, which the system MIME tables may lack.
+var audioContentTypes = map[string]string{
+	".flac": "audio/flac",
+	".mp3":  "audio/mpeg",
+	".mp4":  "audio/mp4",
+	".mpeg": "audio/mpeg",
+	".mpga": "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".ogg":  "audio/ogg",
+	".opus": "audio/ogg",
+	".wav":  "audio/wav",
+	".webm": "audio/webm",
+}
+
+// audioContentType returns the content type of an audio file with the given name.
+func audioContentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if contentType, ok := audioContentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// quoteEscaper escapes a file name for the Content-Disposition header of a form part.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// writeTranscriptionForm writes the fields and the file of a transcription request to the
+// form and closes it.
+func writeTranscriptionForm(form *multipart.Writer, req TranscriptionRequest) error {
+	fields := []struct{ name, value string }{
+		{"model", req.Model},
+		{"language", req.Language},
+		{"prompt", req.Prompt},
+		{"response_format", req.ResponseFormat},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		if err := form.WriteField(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	contentType := audioContentType(req.FileName)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(req.FileName)))
+	header.Set("Content-Type", contentType)
+
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, req.File); err != nil {
+		return err
+	}
+	return form.Close()
+}
@@ -0,0 +1,87 @@
+package groq
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscribe(t *testing.T) {
+	var (
+		fields      map[string]string
+		contentType string
+		audio       string
+	)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/audio/transcriptions", r.URL.Path)
+
+		reader, err := r.MultipartReader()
+		assert.Nil(t, err)
+
+		fields = map[string]string{}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+
+			data, _ := io.ReadAll(part)
+			if part.FormName() == "file" {
+				assert.Equal(t, "hello.mp3", part.FileName())
+				contentType = part.Header.Get("Content-Type")
+				audio = string(data)
+				continue
+			}
+			fields[part.FormName()] = string(data)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if fields["response_format"] == "text" {
+			_, _ = w.Write([]byte("Hello, world!\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"text": "Hello, world!"}`))
+	})
+
+	text, err := c.Transcribe(context.Background(), TranscriptionRequest{
+		File:     strings.NewReader("ID3 audio data"),
+		FileName: "hello.mp3",
+		Language: "en",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello, world!", text)
+	assert.Equal(t, map[string]string{"model": "whisper-large-v3", "language": "en"}, fields)
+	assert.Equal(t, "audio/mpeg", contentType)
+	assert.Equal(t, "ID3 audio data", audio)
+
+	text, err = c.Transcribe(context.Background(), TranscriptionRequest{
+		File:           strings.NewReader("ID3 audio data"),
+		FileName:       "hello.mp3",
+		ResponseFormat: "text",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello, world!\n", text)
+
+	_, err = c.Transcribe(context.Background(), TranscriptionRequest{FileName: "hello.mp3"})
+	assert.NotNil(t, err)
+}
+
+func TestTranscribeError(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusBadRequest, `{"error": {"message": "invalid file format"}}`))
+
+	_, err := c.Transcribe(context.Background(), TranscriptionRequest{
+		File:     strings.NewReader(strings.Repeat("x", 1<<20)),
+		FileName: "hello.xyz",
+	})
+
+	var apiErr *APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
@@ -0,0 +1,62 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchRequest is a chat completion request of a batch.
+type BatchRequest struct {
+	// Messages are the messages of the request.
+	Messages []Message
+	// Options are the options of the request.
+	Options []Option
+}
+
+// BatchResult is the result of a request of a batch.
+type BatchResult struct {
+	// Index is the position of the request in the batch.
+	Index int
+	// Response is the response to the request, nil when Err is set.
+	Response *ChatCompletionResponse
+	// Err is the error of the request.
+	Err error
+}
+
+// BatchChatCompletion sends the requests with at most concurrency of them at the same time,
+// e.g. to stay below a rate limit, and returns their results in the order of the requests.
+// A failed request doesn't abort the batch; its error is set in its result. Once the context
+// is done, the requests that haven't started yet fail with the context error.
+func (c *Client) BatchChatCompletion(ctx context.Context, requests []BatchRequest, concurrency int) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]BatchResult, len(requests))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(requests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := BatchResult{Index: i}
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+				} else {
+					result.Response, result.Err = c.ChatCompletionWithContext(ctx, requests[i].Messages, requests[i].Options...)
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
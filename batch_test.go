@@ -0,0 +1,84 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchChatCompletion(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		active, maxActive int
+	)
+	// The mock server echoes the content of the message and fails for "fail".
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		var body RequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		time.Sleep(10 * time.Millisecond)
+
+		content := body.Messages[0].Content
+		if content == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"index": 0, "message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	})
+
+	contents := []string{"a", "b", "fail", "c", "d", "e", "f"}
+	requests := make([]BatchRequest, len(contents))
+	for i, content := range contents {
+		requests[i] = BatchRequest{Messages: []Message{{Role: "user", Content: content}}}
+	}
+
+	results := c.BatchChatCompletion(context.Background(), requests, 2)
+
+	assert.Equal(t, len(requests), len(results))
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		if contents[i] == "fail" {
+			assert.NotNil(t, result.Err)
+			assert.Nil(t, result.Response)
+			continue
+		}
+		assert.Nil(t, result.Err)
+		assert.Equal(t, contents[i], result.Response.Choices[0].Message.Content)
+	}
+	assert.True(t, maxActive <= 2)
+}
+
+func TestBatchChatCompletionCancel(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123"}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := c.BatchChatCompletion(ctx, []BatchRequest{
+		{Messages: []Message{{Role: "user", Content: "Hello"}}},
+		{Messages: []Message{{Role: "user", Content: "World"}}},
+	}, 4)
+
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
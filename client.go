@@ -2,34 +2,126 @@ package groq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 )
 
+// Version is the version of the groq-go package, reported in the User-Agent header.
+const Version = "0.1.0"
+
+// defaultBaseURL is the base URL of the Groq API.
+const defaultBaseURL = "https://api.groq.com/openai/v1"
+
+// defaultModel is the model used when none is set.
+const defaultModel = "llama3-8b-8192"
+
 // NewClient creates a new client for interacting with the Groq API.
 // It takes the API key as a parameter and returns a pointer to the client.
+// Unless set with options, the API key and the organization are read from the GROQ_API_KEY
+// and GROQ_ORG_ID environment variables.
 func NewClient(options ...ClientOption) *Client {
 	client := &Client{
-		httpClient:        &http.Client{}, // Initialize the HTTP client
-		chatCompletionURL: "https://api.groq.com/openai/v1/chat/completions",
-		apiKey:            os.Getenv("GROQ_API_KEY"),
+		httpClient:   &http.Client{}, // Initialize the HTTP client
+		baseURL:      defaultBaseURL,
+		apiKey:       cleanAPIKey(os.Getenv("GROQ_API_KEY")),
+		organization: strings.TrimSpace(os.Getenv("GROQ_ORG_ID")),
+		userAgent:    "groq-go/" + Version,
+		inflight:     &inflight{},
 	}
 
 	for _, option := range options {
 		option(client)
 	}
 
+	if client.strictKeyValidation && client.err == nil {
+		client.err = validateAPIKey(client.apiKey)
+	}
+
+	if len(client.authRedirectHosts) > 0 {
+		// Copy the HTTP client so a caller's client isn't modified.
+		httpClient := *client.httpClient
+		httpClient.CheckRedirect = authRedirectPolicy(client.authRedirectHosts, httpClient.CheckRedirect)
+		client.httpClient = &httpClient
+	}
+
+	if client.debug != nil {
+		// Copy the HTTP client so a caller's client isn't modified.
+		httpClient := *client.httpClient
+		httpClient.Transport = newDebugTransport(httpClient.Transport, client.debug, client.debugIndent)
+		client.httpClient = &httpClient
+	}
+
 	return client
 }
 
 // ChatCompletion is a function that sends a request to the Groq API for chat completions.
 // It takes a slice of Message as input and returns a pointer to http.Response and an error.
 func (c *Client) ChatCompletion(messages []Message, options ...Option) (*ChatCompletionResponse, error) {
-	body := requestBody{
+	return c.ChatCompletionWithContext(context.Background(), messages, options...)
+}
+
+// ChatCompletionWithContext is like ChatCompletion but sends the request with the given context.
+// When the context is cancelled or its deadline passes, it returns the context error.
+func (c *Client) ChatCompletionWithContext(ctx context.Context, messages []Message, options ...Option) (*ChatCompletionResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	body, err := c.buildBody(messages, options, false)
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := c.Do(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return finishCompletion(body, completion)
+}
+
+// finishCompletion applies the per-request post-processing of a response: stripping code
+// fences and returning a FinishReasonError for the finish reasons set by WithErrorOnFinishReasons.
+func finishCompletion(body RequestBody, completion *ChatCompletionResponse) (*ChatCompletionResponse, error) {
+	if body.stripCodeFences {
+		for i := range completion.Choices {
+			completion.Choices[i].Message.Content = stripCodeFence(completion.Choices[i].Message.Content)
+		}
+	}
+
+	if len(body.errorFinishReasons) > 0 && len(completion.Choices) > 0 {
+		finishReason := completion.Choices[0].FinishReason
+		for _, reason := range body.errorFinishReasons {
+			if finishReason == reason {
+				return nil, &FinishReasonError{FinishReason: finishReason, Response: completion}
+			}
+		}
+	}
+
+	return completion, nil
+}
+
+// ChatCompletionWithKey is like ChatCompletionWithContext but authenticates the request with
+// the given API key instead of the client's, e.g. for a tenant of a multi-tenant gateway.
+// The client itself is not modified.
+func (c *Client) ChatCompletionWithKey(ctx context.Context, apiKey string, messages []Message, options ...Option) (*ChatCompletionResponse, error) {
+	options = append(append([]Option(nil), options...), func(rb *RequestBody) {
+		rb.apiKey = apiKey
+	})
+	return c.ChatCompletionWithContext(ctx, messages, options...)
+}
+
+// applyOptions applies the hardcoded defaults, the profile of the client, the given model
+// defaults and the per-call options, in that order, to a new request body.
+func (c *Client) applyOptions(messages []Message, modelDefaults []Option, options []Option) RequestBody {
+	body := RequestBody{
 		Messages:    messages,
-		Model:       "llama3-8b-8192",
 		Temperature: 1,
 		MaxTokens:   1024,
 		TopP:        1,
@@ -37,88 +129,724 @@ func (c *Client) ChatCompletion(messages []Message, options ...Option) (*ChatCom
 		Stop:        nil,
 	}
 
+	for _, option := range c.profile {
+		option(&body)
+	}
+
+	for _, option := range modelDefaults {
+		option(&body)
+	}
+
 	for _, option := range options {
 		option(&body)
 	}
 
-	jsonData, err := json.Marshal(body)
+	return body
+}
+
+// BuildRequestBody returns the body ChatCompletion would send for the given messages and
+// options, after all defaults and options are applied, without sending it. Checks that
+// would make ChatCompletion fail, such as a missing explicit model, are not applied. The
+// messages are run through the message preprocessor; if it fails, they are left as given.
+func (c *Client) BuildRequestBody(messages []Message, options ...Option) RequestBody {
+	if preprocessed, err := c.preprocessMessages(messages); err == nil {
+		messages = preprocessed
+	}
+	return c.resolveBody(messages, options, false)
+}
+
+// preprocessMessages runs the messages through the message preprocessor, if any.
+func (c *Client) preprocessMessages(messages []Message) ([]Message, error) {
+	if c.messagePreprocessor == nil {
+		return messages, nil
+	}
+	// The preprocessor gets a copy, so that it can't modify the caller's messages.
+	return c.messagePreprocessor(append([]Message(nil), messages...))
+}
+
+// resolveBody resolves the body of a chat completion request from the defaults, the profile
+// of the client and the given options.
+func (c *Client) resolveBody(messages []Message, options []Option, stream bool) RequestBody {
+	body := c.applyOptions(messages, nil, nil)
+	// The per-call options are applied without a model, so that one setting a model, even
+	// the one of the profile, is told apart and bypasses the weighted models.
+	profileModel := body.Model
+	body.Model = ""
+	for _, option := range options {
+		option(&body)
+	}
+	modelSet := body.Model != ""
+	if !modelSet {
+		body.Model = profileModel
+	}
+
+	if len(c.weightedModels) > 0 && !modelSet {
+		if model, ok := pickWeightedModel(c.weightedModels); ok {
+			body.Model = model
+		}
+	}
+
+	if body.Model == "" && !c.requireExplicitModel {
+		body.Model = defaultModel
+	}
+
+	if model, ok := c.modelAliases[body.Model]; ok {
+		body.Model = model
+	}
+
+	// The defaults of a model rank between the profile and the per-call options, so the body
+	// is built again once the model is known.
+	if defaults, ok := c.modelDefaults[body.Model]; ok {
+		model := body.Model
+		body = c.applyOptions(messages, defaults, options)
+		body.Model = model
+	}
+
+	if stream {
+		body.Stream = true
+	}
+
+	if !body.Logprobs {
+		body.TopLogprobs = nil
+	}
+
+	if body.tokenBudget > 0 {
+		budget, promptTokens := tokenBudget(body)
+		if promptTokens < budget {
+			body.MaxTokens = budget - promptTokens
+			if maxOutputTokens, ok := ModelMaxOutputTokens(body.Model); ok && maxOutputTokens < body.MaxTokens {
+				body.MaxTokens = maxOutputTokens
+			}
+		}
+	}
+
+	return body
+}
+
+// tokenBudget returns the token budget of the body, capped at the context window of the
+// model, and the estimated number of prompt tokens.
+func tokenBudget(body RequestBody) (int, int) {
+	budget := body.tokenBudget
+	if contextWindow, ok := ModelContextWindow(body.Model); ok && contextWindow < budget {
+		budget = contextWindow
+	}
+	return budget, estimatePromptTokens(body.Messages)
+}
+
+// buildBody builds the body of a chat completion request and checks it before it is sent.
+func (c *Client) buildBody(messages []Message, options []Option, stream bool) (RequestBody, error) {
+	messages, err := c.preprocessMessages(messages)
+	if err != nil {
+		return RequestBody{}, err
+	}
+
+	body := c.resolveBody(messages, options, stream)
+
+	if body.Model == "" {
+		return RequestBody{}, ErrModelRequired
+	}
+
+	if len(body.Messages) == 0 {
+		return RequestBody{}, ErrNoMessages
+	}
+
+	if err := validateMessages(body.Messages); err != nil {
+		return RequestBody{}, err
+	}
+
+	if body.ResponseFormat.Type == "json_object" && !mentionsJSON(body.Messages) {
+		return RequestBody{}, ErrJSONNotMentioned
+	}
+
+	if err := validateTopLogprobs(body.TopLogprobs); err != nil {
+		return RequestBody{}, err
+	}
+
+	if body.tokenBudget > 0 {
+		if budget, promptTokens := tokenBudget(body); promptTokens >= budget {
+			return RequestBody{}, fmt.Errorf("%w: estimated %d prompt tokens, budget is %d", ErrTokenBudgetExceeded, promptTokens, budget)
+		}
+	}
+
+	if c.strictValidation {
+		if err := body.validate(stream); err != nil {
+			return RequestBody{}, err
+		}
+	}
+
+	return body, nil
+}
+
+// Do sends a fully constructed request body to the Groq API for chat completions.
+// Unlike ChatCompletion, it applies no defaults, options or validation to the body,
+// which is sent as is. Streaming bodies are rejected with ErrStreamNotSupported.
+func (c *Client) Do(ctx context.Context, body RequestBody) (*ChatCompletionResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	// A streamed response can't be decoded as a single JSON object.
+	if body.Stream {
+		return nil, ErrStreamNotSupported
+	}
+
+	if c.dryRun {
+		return nil, newDryRunError(body)
+	}
+
+	if !c.inflight.begin() {
+		return nil, ErrShuttingDown
+	}
+	defer c.inflight.end()
+
+	if body.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, body.timeout)
+		defer cancel()
+	}
+
+	var requestID string
+	if c.requestIDGenerator != nil {
+		requestID = c.requestIDGenerator()
+	}
+
+	var completion *ChatCompletionResponse
+	err := c.retry(ctx, func() error {
+		var err error
+		completion, err = c.send(ctx, body, requestID)
+		return err
+	})
+	if err != nil {
+		if requestID != "" {
+			return nil, &RequestError{RequestID: requestID, Err: err}
+		}
+		return nil, err
+	}
+
+	if c.completionHook != nil {
+		c.completionHook(ctx, CompletionRecord{
+			Model:       body.Model,
+			ModelServed: completion.Model,
+			RequestID:   requestID,
+			Usage:       completion.Usage,
+			Metadata:    MetadataFromContext(ctx),
+		})
+	}
+	return completion, nil
+}
+
+// newDryRunError returns the DryRunError for a request body that isn't sent.
+func newDryRunError(body RequestBody) *DryRunError {
+	promptTokens := estimatePromptTokens(body.Messages)
+	return &DryRunError{
+		Body:         body,
+		PromptTokens: promptTokens,
+		Response: &ChatCompletionResponse{
+			ID:     "dry-run",
+			Object: "chat.completion",
+			Model:  body.Model,
+			Choices: []Choice{{
+				Message:      Message{Role: "assistant"},
+				FinishReason: FinishReasonStop,
+			}},
+			Usage: Usage{PromptTokens: promptTokens, TotalTokens: promptTokens},
+		},
+	}
+}
+
+// send sends the request body to the chat completions endpoint and decodes the response.
+func (c *Client) send(ctx context.Context, body RequestBody, requestID string) (*ChatCompletionResponse, error) {
+	start := time.Now()
+	resp, err := c.post(ctx, body, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := ChatCompletionResponse{}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		partial, ok := decodeLenient(data)
+		if !ok {
+			return nil, err
+		}
+		// The salvaged content is subject to the output filter like any other.
+		if err := c.filterOutput(partial); err != nil {
+			return nil, err
+		}
+		return nil, &PartialResponseError{Err: err, Response: partial}
+	}
+
+	completion.Latency = time.Since(start)
+	completion.RateLimit = parseRateLimit(resp.Header)
+
+	// A streaming chunk (e.g. from a proxy that mixes up the two modes) carries its
+	// content in delta rather than message.
+	for i, choice := range completion.Choices {
+		if choice.Message.isEmpty() && !choice.Delta.isEmpty() {
+			completion.Choices[i].Message = choice.Delta
+			if completion.Choices[i].Message.Role == "" {
+				completion.Choices[i].Message.Role = "assistant"
+			}
+		}
+	}
+
+	if err := c.filterOutput(&completion); err != nil {
+		return nil, err
+	}
+
+	return &completion, nil
+}
+
+// filterOutput runs the output filter on the content of each choice of the response,
+// returning ErrContentBlocked when the filter rejects any of them.
+func (c *Client) filterOutput(completion *ChatCompletionResponse) error {
+	if c.outputFilter == nil {
+		return nil
+	}
+	for i := range completion.Choices {
+		content, ok := c.outputFilter(completion.Choices[i].Message.Content)
+		if !ok {
+			return ErrContentBlocked
+		}
+		completion.Choices[i].Message.Content = content
+	}
+	return nil
+}
+
+// post sends the request body to the chat completions endpoint. The caller must close the
+// body of the returned response, which is only returned for a successful status code.
+func (c *Client) post(ctx context.Context, body RequestBody, requestID string) (*http.Response, error) {
+	header := http.Header{}
+	addHeaders(header, body.headers)
+	if body.apiKey != "" {
+		header.Set("Authorization", "Bearer "+body.apiKey)
+	}
+	if requestID != "" {
+		header.Set("X-Request-ID", requestID)
+	}
+	if body.lastEventID != "" {
+		header.Set("Last-Event-ID", body.lastEventID)
+	}
+	for name, value := range body.headerOverrides {
+		header.Set(name, value)
+	}
+	return c.postJSON(ctx, "/chat/completions", body, header)
+}
+
+// postJSON sends the payload encoded as JSON to the given endpoint, with the given headers
+// added to or replacing the default ones. The caller must close the body of the returned
+// response, which is only returned for a successful status code.
+func (c *Client) postJSON(ctx context.Context, path string, payload interface{}, header http.Header) (*http.Response, error) {
+	var (
+		jsonData []byte
+		err      error
+	)
+	if c.prettyRequestBody {
+		jsonData, err = json.MarshalIndent(payload, "", "  ")
+	} else {
+		jsonData, err = json.Marshal(payload)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", c.chatCompletionURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.setHeaders(req.Header)
+	for name, values := range header {
+		req.Header[name] = values
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return resp, nil
+}
+
+// WithProfile returns a shallow copy of the client whose requests always apply the given
+// options before the per-call options. The original client is left untouched.
+func (c *Client) WithProfile(options ...Option) *Client {
+	clone := *c
+	clone.profile = append(append([]Option(nil), c.profile...), options...)
+	return &clone
+}
+
+// Warmup establishes a connection to the Groq API so that the first real request
+// doesn't pay for the TLS handshake. It sends a cheap GET to the models endpoint and
+// leaves the connection in the HTTP client's pool.
+func (c *Client) Warmup(ctx context.Context) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if !c.inflight.begin() {
+		return ErrShuttingDown
+	}
+	defer c.inflight.end()
+
+	resp, err := c.get(ctx, "/models")
+	if err != nil {
+		return err
+	}
 	defer resp.Body.Close()
 
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// get sends a GET request to the given endpoint path. The caller must close the body of the
+// returned response, which is only returned for a successful status code.
+func (c *Client) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req.Header)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
 	}
 
-	completion := ChatCompletionResponse{}
-	err = json.NewDecoder(resp.Body).Decode(&completion)
+	return resp, nil
+}
+
+// doRequest sends a request with the HTTP client and passes the headers of the response to
+// the response header hook, if any.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	return &completion, nil
+	if c.responseHeaderHook != nil {
+		c.responseHeaderHook(resp.Header)
+	}
+	return resp, nil
+}
+
+// setHeaders sets the headers sent with every request: the authentication, the user agent,
+// the organization and the headers set with WithDefaultHeaders.
+func (c *Client) setHeaders(header http.Header) {
+	header.Set("Authorization", "Bearer "+c.apiKey)
+	header.Set("User-Agent", c.userAgent)
+	if c.organization != "" {
+		header.Set(organizationHeader, c.organization)
+	}
+	addHeaders(header, c.headers)
 }
 
 // WithModel sets the model for the request body.
-func WithModel(model string) func(*requestBody) {
-	return func(rb *requestBody) {
+func WithModel(model string) func(*RequestBody) {
+	return func(rb *RequestBody) {
 		rb.Model = model
 	}
 }
 
 // WithTemperature sets the temperature for the request body.
-func WithTemperature(temperature float64) func(*requestBody) {
-	return func(rb *requestBody) {
+func WithTemperature(temperature float64) func(*RequestBody) {
+	return func(rb *RequestBody) {
 		rb.Temperature = temperature
 	}
 }
 
 // WithMaxTokens sets the maximum number of tokens for the request body.
-func WithMaxTokens(maxTokens int) func(*requestBody) {
-	return func(rb *requestBody) {
+func WithMaxTokens(maxTokens int) func(*RequestBody) {
+	return func(rb *RequestBody) {
 		rb.MaxTokens = maxTokens
 	}
 }
 
 // WithTopP sets the top_p value for the request body.
-func WithTopP(topP float64) func(*requestBody) {
-	return func(rb *requestBody) {
+func WithTopP(topP float64) func(*RequestBody) {
+	return func(rb *RequestBody) {
 		rb.TopP = topP
 	}
 }
 
+// WithN sets the number of choices to generate for the request body.
+func WithN(n int) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.N = n
+	}
+}
+
+// WithLogprobs requests the log probabilities of the generated tokens, returned in the
+// Logprobs of each choice.
+func WithLogprobs(logprobs bool) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.Logprobs = logprobs
+	}
+}
+
+// WithTopLogprobs sets the number of most likely tokens, between 0 and 20, returned with the
+// log probability of each token. It is only sent along with WithLogprobs(true).
+func WithTopLogprobs(n int) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.TopLogprobs = &n
+	}
+}
+
+// WithFrequencyPenalty sets the frequency_penalty value for the request body.
+func WithFrequencyPenalty(penalty float64) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.FrequencyPenalty = &penalty
+	}
+}
+
+// WithPresencePenalty sets the presence_penalty value for the request body.
+func WithPresencePenalty(penalty float64) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.PresencePenalty = &penalty
+	}
+}
+
+// WithTokenBudget sets the total number of tokens the request may use. The maximum number of
+// tokens to generate is derived from it by subtracting an estimate of the prompt tokens, and
+// ChatCompletion returns ErrTokenBudgetExceeded when the prompt alone exceeds the budget.
+// For known models the budget is capped at the context window and the maximum number of
+// tokens at the output limit. It overrides WithMaxTokens.
+func WithTokenBudget(total int) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.tokenBudget = total
+	}
+}
+
+// WithTimeout limits the duration of the request, including retries, independently of the
+// timeout of the HTTP client. For a streaming request it covers the whole stream. If the
+// context of the request has an earlier deadline, that one applies.
+func WithTimeout(timeout time.Duration) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.timeout = timeout
+	}
+}
+
+// WithStripCodeFences removes the code fence, e.g. ```json ... ```, around the content of
+// each choice of the response. Only a single fence wrapping the whole content is removed;
+// content with text around a fence or with several code blocks is left intact.
+func WithStripCodeFences() func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.stripCodeFences = true
+	}
+}
+
+// WithOnFinish sets a function that ChatCompletionStreamCallback and ChatCompletionStreamTo
+// call once with the assembled response, including the usage when the API reports it, when
+// the stream ends or is stopped with ErrStopStream, e.g. to record metrics from a goroutine
+// that doesn't inspect the return value. It isn't called when the stream fails. Other
+// methods ignore it; WithCompletionHook records the usage of every completion instead.
+func WithOnFinish(fn func(*ChatCompletionResponse)) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.onFinish = fn
+	}
+}
+
+// stripCodeFence removes a single code fence wrapping the whole content.
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return content
+	}
+
+	// The opening fence is on a line of its own, optionally with a language.
+	newline := strings.IndexByte(trimmed, '\n')
+	if newline < 0 || strings.Contains(trimmed[3:newline], "`") {
+		return content
+	}
+
+	inner := strings.TrimSuffix(trimmed[newline+1:len(trimmed)-3], "\n")
+	// Another fence inside means that the content has several code blocks.
+	if strings.HasPrefix(inner, "```") || strings.Contains(inner, "\n```") {
+		return content
+	}
+	return inner
+}
+
+// WithHeaders adds headers to the request, e.g. a tracing header, replacing those set with
+// WithDefaultHeaders. Authorization and Content-Type are not replaced; use
+// WithHeaderOverride to replace them on purpose.
+func WithHeaders(headers map[string]string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.headers = mergeHeaders(rb.headers, headers)
+	}
+}
+
+// WithHeaderOverride sets a header of the request, replacing any value set by the client,
+// including the Authorization and Content-Type headers.
+func WithHeaderOverride(name, value string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.headerOverrides = mergeHeaders(rb.headerOverrides, map[string]string{name: value})
+	}
+}
+
+// WithErrorOnFinishReasons makes ChatCompletion and ChatCompletionStreamCallback return a
+// *FinishReasonError when the finish reason of the first choice is one of the given reasons,
+// e.g. FinishReasonContentFilter or FinishReasonLength.
+func WithErrorOnFinishReasons(reasons ...FinishReason) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.errorFinishReasons = append(rb.errorFinishReasons, reasons...)
+	}
+}
+
+// WithStreamUsage sets whether a streamed response includes the usage statistics of the
+// completion in its last chunk, which are then available from ChatCompletionStream.Usage.
+func WithStreamUsage(includeUsage bool) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.StreamOptions = &StreamOptions{IncludeUsage: includeUsage}
+	}
+}
+
+// WithTools sets the tools the model may call.
+func WithTools(tools ...Tool) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.Tools = append(rb.Tools, tools...)
+	}
+}
+
+// WithToolChoice sets which tool the model calls: "none", "auto" or "required".
+// Use WithToolChoiceFunction to force a call of a specific function.
+func WithToolChoice(choice string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.ToolChoice = choice
+	}
+}
+
+// WithToolChoiceFunction forces the model to call the function with the given name.
+func WithToolChoiceFunction(name string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.ToolChoice = map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": name},
+		}
+	}
+}
+
 // WithJSON sets the response format to json_type for the request body.
-func WithJSON() func(*requestBody) {
-	return func(rb *requestBody) {
+func WithJSON() func(*RequestBody) {
+	return func(rb *RequestBody) {
 		rb.ResponseFormat.Type = "json_object"
 		rb.Stream = false
 	}
 }
 
-// WithSeed sets the seed value for the request body.
-func WithSeed(seed int) func(*requestBody) {
-	return func(rb *requestBody) {
-		rb.Seed = seed
+// WithJSONMode makes the model return a valid JSON object. The API rejects JSON mode
+// requests whose messages don't mention JSON, so ChatCompletion returns
+// ErrJSONNotMentioned for them before sending.
+func WithJSONMode() func(*RequestBody) {
+	return WithResponseFormat("json_object")
+}
+
+// WithResponseFormat sets the type of the response format, e.g. "text" or "json_object".
+func WithResponseFormat(formatType string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.ResponseFormat.Type = formatType
+	}
+}
+
+// mentionsJSON reports whether any of the messages mentions JSON.
+func mentionsJSON(messages []Message) bool {
+	for _, message := range messages {
+		if strings.Contains(strings.ToLower(message.Content), "json") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonPhrases are the phrases that make WithAutoJSONMode enable JSON mode.
+var jsonPhrases = []string{
+	"respond with json",
+	"respond in json",
+	"reply with json",
+	"reply in json",
+	"return json",
+	"return a json",
+	"output json",
+	"in json format",
+}
+
+// WithAutoJSONMode enables JSON mode when the last user message asks for JSON, e.g.
+// "respond with JSON" or "return a JSON object". The check is a simple heuristic, so it
+// is opt-in. It must come after any option that changes the messages.
+func WithAutoJSONMode() func(*RequestBody) {
+	return func(rb *RequestBody) {
+		for i := len(rb.Messages) - 1; i >= 0; i-- {
+			if rb.Messages[i].Role != "user" {
+				continue
+			}
+
+			content := strings.ToLower(rb.Messages[i].Content)
+			for _, phrase := range jsonPhrases {
+				if strings.Contains(content, phrase) {
+					WithJSON()(rb)
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+// WithMergeSystemMessages merges all system messages into a single system message, joined
+// by newlines, at the position of the first one. It must come after any option that
+// changes the messages.
+func WithMergeSystemMessages() func(*RequestBody) {
+	return func(rb *RequestBody) {
+		var (
+			messages []Message
+			system   = -1
+		)
+		for _, message := range rb.Messages {
+			if message.Role != "system" {
+				messages = append(messages, message)
+				continue
+			}
+			if system < 0 {
+				system = len(messages)
+				messages = append(messages, message)
+				continue
+			}
+			messages[system].Content += "\n" + message.Content
+		}
+		rb.Messages = messages
+	}
+}
+
+// WithSeed sets the seed value for the request body. The same seed, parameters and
+// system fingerprint give nearly deterministic outputs.
+func WithSeed(seed int) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.Seed = &seed
 	}
 }
 
-// WithStop sets the stop sequence for the request body.
-func WithStop(stop string) func(*requestBody) {
-	return func(rb *requestBody) {
-		rb.Stop = &stop
+// WithStop sets the stop sequences for the request body. The API accepts up to four.
+func WithStop(stops ...string) func(*RequestBody) {
+	return func(rb *RequestBody) {
+		rb.Stop = append(Stop(nil), stops...)
 	}
 }
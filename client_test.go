@@ -1,11 +1,17 @@
 package groq
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,7 +31,7 @@ func TestChatCompletion(t *testing.T) {
 		}))
 		defer ts.Close()
 
-		c.chatCompletionURL = ts.URL
+		c.baseURL = ts.URL
 		c.httpClient = ts.Client()
 
 		// Test data
@@ -45,7 +51,7 @@ func TestChatCompletion(t *testing.T) {
 		assert.Equal(t, "llama3-8b-8192", completion.Model)
 		assert.Equal(t, 1, len(completion.Choices))
 		assert.Equal(t, "Hello, world!", completion.Choices[0].Message.Content)
-		assert.Equal(t, "length", completion.Choices[0].FinishReason)
+		assert.Equal(t, FinishReasonLength, completion.Choices[0].FinishReason)
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -78,3 +84,966 @@ func TestChatCompletion(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+// newTestClient returns a client that sends its requests to a mock server backed by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc, options ...ClientOption) *Client {
+	t.Helper()
+
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	c := NewClient(append([]ClientOption{WithAPIKey("test-key")}, options...)...)
+	c.baseURL = ts.URL
+	c.httpClient = ts.Client()
+	return c
+}
+
+// respondWith returns a handler that replies with the given status code and body.
+func respondWith(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestOutputFilter(t *testing.T) {
+	body := `{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "content": "my secret is 42"}, "finish_reason": "stop"}]}`
+
+	t.Run("Transform", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, body), WithOutputFilter(func(s string) (string, bool) {
+			return strings.ReplaceAll(s, "42", "[redacted]"), true
+		}))
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "my secret is [redacted]", completion.Choices[0].Message.Content)
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, body), WithOutputFilter(func(s string) (string, bool) {
+			return s, !strings.Contains(s, "secret")
+		}))
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, completion)
+		assert.ErrorIs(t, err, ErrContentBlocked)
+	})
+}
+
+func TestWarmup(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var path string
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+		})
+
+		err := c.Warmup(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/models", path)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusUnauthorized, `{}`))
+
+		err := c.Warmup(context.Background())
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestServiceTier(t *testing.T) {
+	t.Run("Present", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "service_tier": "on_demand"}`))
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "on_demand", completion.ServiceTier)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123"}`))
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "", completion.ServiceTier)
+	})
+}
+
+func TestWithProfile(t *testing.T) {
+	var model string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model          string `json:"model"`
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model = body.Model + "/" + body.ResponseFormat.Type
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+
+	jsonClient := c.WithProfile(WithJSON(), WithModel("llama3-70b-8192"))
+	messages := []Message{{Role: "user", Content: "Say hello in JSON."}}
+
+	_, err := jsonClient.ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Equal(t, "llama3-70b-8192/json_object", model)
+
+	// Per-call options still override the profile.
+	_, err = jsonClient.ChatCompletion(messages, WithModel("mixtral-8x7b-32768"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mixtral-8x7b-32768/json_object", model)
+
+	// The original client is unaffected.
+	_, err = c.ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Equal(t, "llama3-8b-8192/", model)
+}
+
+func TestLatency(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123", "usage": {"total_time": 0.001}}`))
+	})
+
+	completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.Nil(t, err)
+	assert.True(t, completion.Latency >= 10*time.Millisecond)
+	assert.True(t, completion.Latency > completion.TotalLatency())
+}
+
+func TestDryRun(t *testing.T) {
+	requests := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}, WithDryRun())
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages, WithMaxTokens(100))
+
+	var dryRunErr *DryRunError
+	assert.True(t, errors.As(err, &dryRunErr))
+	assert.Equal(t, 0, requests)
+	assert.Equal(t, "llama3-8b-8192", dryRunErr.Body.Model)
+	assert.Equal(t, 100, dryRunErr.Body.MaxTokens)
+	assert.Equal(t, estimatePromptTokens(messages), dryRunErr.PromptTokens)
+	assert.Equal(t, dryRunErr.PromptTokens, dryRunErr.Response.Usage.PromptTokens)
+	assert.Equal(t, "assistant", dryRunErr.Response.Choices[0].Message.Role)
+
+	_, err = c.ChatCompletionStream(context.Background(), messages)
+	assert.True(t, errors.As(err, &dryRunErr))
+	assert.True(t, dryRunErr.Body.Stream)
+	assert.Equal(t, 0, requests)
+
+	// Invalid requests still fail as usual.
+	_, err = c.ChatCompletion(messages, WithJSONMode())
+	assert.ErrorIs(t, err, ErrJSONNotMentioned)
+}
+
+func TestMessageName(t *testing.T) {
+	data, err := json.Marshal([]Message{
+		{Role: "user", Name: "alice", Content: "Hi!"},
+		{Role: "user", Content: "Hello!"},
+	})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[{"role": "user", "name": "alice", "content": "Hi!"}, {"role": "user", "content": "Hello!"}]`, string(data))
+}
+
+func TestPartialResponse(t *testing.T) {
+	// The usage has an unexpected shape.
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "model": "llama3-8b-8192", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello!"}, "finish_reason": "stop"}], "usage": {"total_tokens": "many"}}`))
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	var partialErr *PartialResponseError
+	assert.True(t, errors.As(err, &partialErr))
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, "123", partialErr.Response.ID)
+	assert.Equal(t, "llama3-8b-8192", partialErr.Response.Model)
+	assert.Equal(t, "Hello!", partialErr.Response.Choices[0].Message.Content)
+	assert.Equal(t, FinishReasonStop, partialErr.Response.Choices[0].FinishReason)
+
+	// Invalid JSON can't be salvaged.
+	c = newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "choices": [`))
+
+	_, err = c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.NotNil(t, err)
+	assert.False(t, errors.As(err, &partialErr))
+}
+
+func TestPartialResponseOutputFilter(t *testing.T) {
+	filter := WithOutputFilter(func(content string) (string, bool) {
+		if strings.Contains(content, "BANNED") {
+			return "", false
+		}
+		return strings.ReplaceAll(content, "secret", "[scrubbed]"), true
+	})
+	partial := func(content string) http.HandlerFunc {
+		return respondWith(http.StatusOK, `{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "content": "`+content+`"}}], "usage": {"total_tokens": "many"}}`)
+	}
+
+	c := newTestClient(t, partial("BANNED"), filter)
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+	assert.ErrorIs(t, err, ErrContentBlocked)
+
+	c = newTestClient(t, partial("the secret"), filter)
+	_, err = c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	var partialErr *PartialResponseError
+	assert.True(t, errors.As(err, &partialErr))
+	assert.Equal(t, "the [scrubbed]", partialErr.Response.Choices[0].Message.Content)
+}
+
+func TestDefaultOptions(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	// Hardcoded defaults.
+	body := NewClient().BuildRequestBody(messages)
+	assert.Equal(t, "llama3-8b-8192", body.Model)
+	assert.Equal(t, float64(1), body.Temperature)
+	assert.Equal(t, 1024, body.MaxTokens)
+
+	// Client defaults override the hardcoded defaults.
+	c := NewClient(
+		WithDefaultModel("llama3-70b-8192"),
+		WithDefaultTemperature(0.2),
+		WithDefaultMaxTokens(256),
+		WithDefaultOptions(WithSeed(7)),
+	)
+	body = c.BuildRequestBody(messages)
+	assert.Equal(t, "llama3-70b-8192", body.Model)
+	assert.Equal(t, 0.2, body.Temperature)
+	assert.Equal(t, 256, body.MaxTokens)
+	assert.Equal(t, 7, *body.Seed)
+	assert.Equal(t, float64(1), body.TopP)
+
+	// Per-call options override the client defaults.
+	body = c.BuildRequestBody(messages, WithModel("mixtral-8x7b-32768"), WithTemperature(0.9))
+	assert.Equal(t, "mixtral-8x7b-32768", body.Model)
+	assert.Equal(t, 0.9, body.Temperature)
+	assert.Equal(t, 256, body.MaxTokens)
+}
+
+func TestMessagePreprocessor(t *testing.T) {
+	var content string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body RequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		content = body.Messages[0].Content
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}, WithMessagePreprocessor(func(messages []Message) ([]Message, error) {
+		for i := range messages {
+			if strings.Contains(messages[i].Content, "secret") {
+				return nil, errors.New("message contains a secret")
+			}
+			messages[i].Content = strings.ReplaceAll(messages[i].Content, "alice@example.com", "[email]")
+		}
+		return messages, nil
+	}))
+
+	messages := []Message{{Role: "user", Content: "Mail alice@example.com"}}
+	_, err := c.ChatCompletion(messages)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Mail [email]", content)
+	assert.Equal(t, "Mail alice@example.com", messages[0].Content)
+
+	content = ""
+	_, err = c.ChatCompletion([]Message{{Role: "user", Content: "The secret is 42"}})
+
+	assert.EqualError(t, err, "message contains a secret")
+	assert.Equal(t, "", content)
+
+	body := c.BuildRequestBody(messages)
+	assert.Equal(t, "Mail [email]", body.Messages[0].Content)
+	assert.Equal(t, "Mail alice@example.com", messages[0].Content)
+}
+
+func TestWithTimeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	start := time.Now()
+	_, err := c.ChatCompletion(messages, WithTimeout(20*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+
+	// The earlier deadline of the context wins.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = c.ChatCompletionWithContext(ctx, messages, WithTimeout(time.Minute))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(start) < 200*time.Millisecond)
+
+	_, err = c.ChatCompletionStream(context.Background(), messages, WithTimeout(20*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "JSON", content: "```json\n{\"a\": 1}\n```", expected: `{"a": 1}`},
+		{name: "NoLanguage", content: "\n```\nfmt.Println()\n```\n", expected: "fmt.Println()"},
+		{name: "Multiline", content: "```go\nfunc main() {\n}\n```", expected: "func main() {\n}"},
+		{name: "NoFence", content: `{"a": 1}`, expected: `{"a": 1}`},
+		{name: "TextAround", content: "Here you go:\n```json\n{}\n```", expected: "Here you go:\n```json\n{}\n```"},
+		{name: "SeveralBlocks", content: "```go\na()\n```\nand\n```go\nb()\n```", expected: "```go\na()\n```\nand\n```go\nb()\n```"},
+		{name: "Inline", content: "```x``` and ```y```", expected: "```x``` and ```y```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stripCodeFence(tt.content))
+		})
+	}
+}
+
+func TestWithStripCodeFences(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "content": "`+"```json\\n{}\\n```"+`"}}]}`))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	completion, err := c.ChatCompletion(messages, WithStripCodeFences())
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", completion.Choices[0].Message.Content)
+
+	completion, err = c.ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Equal(t, "```json\n{}\n```", completion.Choices[0].Message.Content)
+}
+
+func TestDeltaFallback(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "object": "chat.completion.chunk", "choices": [{"index": 0, "delta": {"content": "Hello!"}, "finish_reason": "stop"}]}`))
+
+	completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello!", completion.Choices[0].Message.Content)
+	assert.Equal(t, "assistant", completion.Choices[0].Message.Role)
+}
+
+func TestUserAgent(t *testing.T) {
+	var userAgent string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Default", func(t *testing.T) {
+		c := newTestClient(t, handler)
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "groq-go/"+Version, userAgent)
+	})
+
+	t.Run("Suffix", func(t *testing.T) {
+		c := newTestClient(t, handler, WithUserAgentSuffix("my-app/1.2"))
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "groq-go/"+Version+" my-app/1.2", userAgent)
+	})
+}
+
+func TestWithOrganization(t *testing.T) {
+	var organizations []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		organizations = append(organizations, r.Header.Get("Groq-Organization"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}, WithOrganization("org_123"))
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	err = c.Warmup(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"org_123", "org_123"}, organizations)
+}
+
+func TestRequireExplicitModel(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123"}`), WithRequireExplicitModel())
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages)
+	assert.ErrorIs(t, err, ErrModelRequired)
+
+	_, err = c.ChatCompletion(messages, WithModel("llama3-70b-8192"))
+	assert.Nil(t, err)
+}
+
+func TestAPIKeyNotInErrors(t *testing.T) {
+	const apiKey = "gsk_super_secret_key"
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Status", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusUnauthorized, `{"error": {"message": "Invalid API Key"}}`), WithAPIKey(apiKey))
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), apiKey)
+	})
+
+	t.Run("Transport", func(t *testing.T) {
+		ts := httptest.NewServer(respondWith(http.StatusOK, `{}`))
+		ts.Close()
+
+		c := NewClient(WithAPIKey(apiKey))
+		c.baseURL = ts.URL
+
+		_, err := c.ChatCompletion(messages)
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), apiKey)
+
+		err = c.Warmup(context.Background())
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), apiKey)
+	})
+
+	t.Run("InvalidHeader", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, `{}`), WithAPIKey(apiKey+"\n"))
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.NotNil(t, err)
+		assert.NotContains(t, err.Error(), apiKey)
+	})
+}
+
+func TestModelAliases(t *testing.T) {
+	var model string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model = body.Model
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}, WithModelAliases(map[string]string{"fast": "llama3-8b-8192", "smart": "llama3-70b-8192"}))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages, WithModel("smart"))
+	assert.Nil(t, err)
+	assert.Equal(t, "llama3-70b-8192", model)
+
+	_, err = c.ChatCompletion(messages, WithModel("mixtral-8x7b-32768"))
+	assert.Nil(t, err)
+	assert.Equal(t, "mixtral-8x7b-32768", model)
+}
+
+func TestResponseHeaderHook(t *testing.T) {
+	var requestIDs []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_123")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, WithResponseHeaderHook(func(h http.Header) {
+		requestIDs = append(requestIDs, h.Get("X-Request-Id"))
+	}))
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+	assert.NotNil(t, err)
+
+	// The other endpoints are covered too.
+	_, err = c.ListModels(context.Background())
+	assert.NotNil(t, err)
+
+	assert.Equal(t, []string{"req_123", "req_123"}, requestIDs)
+}
+
+func TestTokenBudget(t *testing.T) {
+	var maxTokens int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			MaxTokens int `json:"max_tokens"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		maxTokens = body.MaxTokens
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+	messages := []Message{{Role: "user", Content: strings.Repeat("a", 400)}}
+
+	_, err := c.ChatCompletion(messages, WithTokenBudget(1000))
+	assert.Nil(t, err)
+	assert.Equal(t, 1000-estimatePromptTokens(messages), maxTokens)
+
+	_, err = c.ChatCompletion(messages, WithTokenBudget(50))
+	assert.ErrorIs(t, err, ErrTokenBudgetExceeded)
+
+	// The budget is capped at the context window of the model.
+	_, err = c.ChatCompletion(messages, WithTokenBudget(100000))
+	assert.Nil(t, err)
+	assert.Equal(t, 8192-estimatePromptTokens(messages), maxTokens)
+}
+
+func TestAutoJSONMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		expected string
+	}{
+		{
+			name:     "Requested",
+			messages: []Message{{Role: "user", Content: "List three colors. Respond with JSON only."}},
+			expected: "json_object",
+		},
+		{
+			name:     "NotRequested",
+			messages: []Message{{Role: "user", Content: "List three colors."}},
+			expected: "",
+		},
+		{
+			name: "OnlyLastUserMessage",
+			messages: []Message{
+				{Role: "user", Content: "Return a JSON object with colors."},
+				{Role: "assistant", Content: `{"colors": []}`},
+				{Role: "user", Content: "Now describe them in prose."},
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := RequestBody{Messages: tt.messages}
+			WithAutoJSONMode()(&body)
+			assert.Equal(t, tt.expected, body.ResponseFormat.Type)
+		})
+	}
+}
+
+func TestJSONMode(t *testing.T) {
+	var format string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		format = body.ResponseFormat.Type
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+
+	_, err := c.ChatCompletion([]Message{
+		{Role: "system", Content: "Reply with a Json object."},
+		{Role: "user", Content: "List three colors."},
+	}, WithJSONMode())
+	assert.Nil(t, err)
+	assert.Equal(t, "json_object", format)
+
+	format = "unset"
+	_, err = c.ChatCompletion([]Message{{Role: "user", Content: "List three colors."}}, WithJSONMode())
+	assert.ErrorIs(t, err, ErrJSONNotMentioned)
+	assert.Equal(t, "unset", format)
+
+	_, err = c.ChatCompletion([]Message{{Role: "user", Content: "List three colors."}}, WithResponseFormat("text"))
+	assert.Nil(t, err)
+	assert.Equal(t, "text", format)
+}
+
+func TestMergeSystemMessages(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You're a seasoned developer"},
+		{Role: "user", Content: "What is groq cloud?"},
+		{Role: "system", Content: "Answer in one sentence"},
+	}
+	body := RequestBody{Messages: messages}
+
+	WithMergeSystemMessages()(&body)
+
+	assert.Equal(t, []Message{
+		{Role: "system", Content: "You're a seasoned developer\nAnswer in one sentence"},
+		{Role: "user", Content: "What is groq cloud?"},
+	}, body.Messages)
+	// The caller's messages are left untouched.
+	assert.Equal(t, "You're a seasoned developer", messages[0].Content)
+}
+
+func TestDo(t *testing.T) {
+	var received map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}).WithProfile(WithModel("ignored"))
+
+	completion, err := c.Do(context.Background(), RequestBody{
+		Messages:    []Message{{Role: "user", Content: "Hello, world!"}},
+		Model:       "llama3-70b-8192",
+		MaxTokens:   10,
+		Temperature: 0.2,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "123", completion.ID)
+	// No defaults or profile options are applied.
+	assert.Equal(t, "llama3-70b-8192", received["model"])
+	assert.Equal(t, 0.2, received["temperature"])
+	assert.Equal(t, 0.0, received["top_p"])
+}
+
+func TestRequestIDGenerator(t *testing.T) {
+	var requestID string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusBadRequest)
+	}, WithRequestIDGenerator(func() string { return "req-42" }))
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.Equal(t, "req-42", requestID)
+	var requestErr *RequestError
+	assert.True(t, errors.As(err, &requestErr))
+	assert.Equal(t, "req-42", requestErr.RequestID)
+}
+
+func TestPrettyRequestBody(t *testing.T) {
+	var raw string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		raw = string(data)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := newTestClient(t, handler).ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.NotContains(t, raw, "\n")
+
+	_, err = newTestClient(t, handler, WithPrettyRequestBody()).ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Contains(t, raw, "\n  \"messages\": [")
+}
+
+func TestChatCompletionWithContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.ChatCompletionWithContext(ctx, []Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAPIError(t *testing.T) {
+	t.Run("Body", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusNotFound, `{"error": {"message": "The model llama9 does not exist", "type": "invalid_request_error", "code": "model_not_found"}}`))
+
+		_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}}, WithModel("llama9"))
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "model_not_found", apiErr.Code)
+		assert.Equal(t, "invalid_request_error", apiErr.Type)
+		assert.Equal(t, "The model llama9 does not exist", apiErr.Message)
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusBadGateway, `<html>Bad Gateway</html>`))
+
+		_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, "unexpected status code: 502", err.Error())
+	})
+}
+
+func TestErrorOnFinishReasons(t *testing.T) {
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Once upon a"}, "finish_reason": "length"}]}`))
+	messages := []Message{{Role: "user", Content: "Tell me a story"}}
+
+	_, err := c.ChatCompletion(messages, WithErrorOnFinishReasons("content_filter"))
+	assert.Nil(t, err)
+
+	_, err = c.ChatCompletion(messages, WithErrorOnFinishReasons("content_filter", "length"))
+	var finishErr *FinishReasonError
+	assert.True(t, errors.As(err, &finishErr))
+	assert.Equal(t, FinishReasonLength, finishErr.FinishReason)
+	assert.Equal(t, "123", finishErr.Response.ID)
+}
+
+func TestChatCompletionWithKey(t *testing.T) {
+	var authorization string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletionWithKey(context.Background(), "tenant-key", messages)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer tenant-key", authorization)
+
+	_, err = c.ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer test-key", authorization)
+}
+
+func TestClientOptions(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(WithAPIKey("test-key"), WithBaseURL(ts.URL+"/proxy/openai/v1/"), WithHTTPClient(ts.Client()))
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "/proxy/openai/v1/chat/completions", path)
+
+	err = c.Warmup(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "/proxy/openai/v1/models", path)
+}
+
+func TestWaitForModel(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Loading", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"error": {"message": "Model is currently loading", "code": "model_loading", "estimated_time": 0.01}}`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": "123"}`))
+		}, WithWaitForModel())
+
+		completion, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "123", completion.ID)
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"message": "Service unavailable"}}`))
+		}, WithWaitForModel())
+
+		_, err := c.ChatCompletion(messages)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.False(t, apiErr.IsModelLoading())
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestWithStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		stops    []string
+		expected string
+	}{
+		{name: "None", stops: nil, expected: `null`},
+		{name: "Single", stops: []string{"\n"}, expected: `"\n"`},
+		{name: "Multiple", stops: []string{"END", "STOP"}, expected: `["END","STOP"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body RequestBody
+			WithStop(tt.stops...)(&body)
+
+			data, err := json.Marshal(body)
+			assert.Nil(t, err)
+
+			var fields map[string]json.RawMessage
+			assert.Nil(t, json.Unmarshal(data, &fields))
+			stop, ok := fields["stop"]
+			if !ok {
+				stop = json.RawMessage(`null`)
+			}
+			assert.Equal(t, tt.expected, string(stop))
+
+			var decoded RequestBody
+			assert.Nil(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, len(tt.stops), len(decoded.Stop))
+		})
+	}
+}
+
+func TestWithSeed(t *testing.T) {
+	var body RequestBody
+	data, err := json.Marshal(body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), `"seed"`)
+
+	WithSeed(0)(&body)
+	data, err = json.Marshal(body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"seed":0`)
+}
+
+func TestPenalties(t *testing.T) {
+	var body RequestBody
+	data, err := json.Marshal(body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "penalty")
+
+	WithFrequencyPenalty(0.5)(&body)
+	WithPresencePenalty(0)(&body)
+	data, err = json.Marshal(body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"frequency_penalty":0.5`)
+	assert.Contains(t, string(data), `"presence_penalty":0`)
+}
+
+func TestWithN(t *testing.T) {
+	var body RequestBody
+	data, err := json.Marshal(body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), `"n"`)
+
+	WithN(2)(&body)
+	data, err = json.Marshal(body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"n":2`)
+}
+
+func TestWithLogprobs(t *testing.T) {
+	c := NewClient()
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	body := c.BuildRequestBody(messages, WithLogprobs(true), WithTopLogprobs(0))
+	data, err := json.Marshal(body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"logprobs":true`)
+	assert.Contains(t, string(data), `"top_logprobs":0`)
+
+	// top_logprobs is only sent along with logprobs.
+	body = c.BuildRequestBody(messages, WithTopLogprobs(5))
+	data, err = json.Marshal(body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "logprobs")
+
+	// An out of range top_logprobs fails without strict validation.
+	_, err = c.ChatCompletion(messages, WithLogprobs(true), WithTopLogprobs(21))
+	assert.EqualError(t, err, "groq: top_logprobs must be between 0 and 20, got 21")
+}
+
+func TestChatCompletionRejectsStream(t *testing.T) {
+	requests := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		streamHandler("data: [DONE]\n\n")(w, r)
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages, func(rb *RequestBody) { rb.Stream = true })
+	assert.ErrorIs(t, err, ErrStreamNotSupported)
+
+	_, err = c.Do(context.Background(), RequestBody{Messages: messages, Model: "llama3-8b-8192", Stream: true})
+	assert.ErrorIs(t, err, ErrStreamNotSupported)
+
+	assert.Equal(t, 0, requests)
+}
+
+func TestModelDefaults(t *testing.T) {
+	var body RequestBody
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body = RequestBody{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}, WithModelDefaults("llama3-70b-8192", WithTemperature(0.1), WithMaxTokens(2048)))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages, WithModel("llama3-70b-8192"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.1, body.Temperature)
+	assert.Equal(t, 2048, body.MaxTokens)
+
+	// Per-call options take precedence over the model defaults.
+	_, err = c.ChatCompletion(messages, WithModel("llama3-70b-8192"), WithTemperature(0.9))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.9, body.Temperature)
+	assert.Equal(t, 2048, body.MaxTokens)
+
+	// Other models are unaffected.
+	_, err = c.ChatCompletion(messages)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, body.Temperature)
+	assert.Equal(t, 1024, body.MaxTokens)
+}
+
+func TestBuildRequestBody(t *testing.T) {
+	c := NewClient(WithAPIKey("test-key"), WithModelAliases(map[string]string{"smart": "llama3-70b-8192"}))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	body := c.BuildRequestBody(messages, WithModel("smart"), WithTemperature(0.3), WithStop("END"))
+
+	assert.Equal(t, messages, body.Messages)
+	assert.Equal(t, "llama3-70b-8192", body.Model)
+	assert.Equal(t, 0.3, body.Temperature)
+	assert.Equal(t, 1024, body.MaxTokens)
+	assert.Equal(t, 1.0, body.TopP)
+	assert.Equal(t, Stop{"END"}, body.Stop)
+	assert.False(t, body.Stream)
+}
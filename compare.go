@@ -0,0 +1,96 @@
+package groq
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// StreamEvent is a chunk of a stream of StreamCompare, or the error that ended the stream.
+type StreamEvent struct {
+	// Chunk is the chunk of the stream, nil when Err is set.
+	Chunk *ChatCompletionStreamResponse
+	// Err is the error that ended the stream. The channel is closed without an error when
+	// the stream ends normally.
+	Err error
+}
+
+// StreamCompare streams the same messages from several models at once, e.g. to compare them
+// side by side, and returns the channel of events of each model. Each channel is closed
+// once its stream ends. If a stream can't be opened, the others are closed and the error is
+// returned. A model listed more than once is streamed once. Cancelling the context tears
+// down all streams and closes the channels, which need not be drained then.
+func (c *Client) StreamCompare(ctx context.Context, messages []Message, models []string, options ...Option) (map[string]<-chan StreamEvent, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		streams  = make(map[string]*ChatCompletionStream, len(models))
+	)
+
+	seen := make(map[string]bool, len(models))
+	for _, model := range models {
+		if seen[model] {
+			continue
+		}
+		seen[model] = true
+
+		wg.Add(1)
+		go func(model string) {
+			defer wg.Done()
+
+			opts := append(append([]Option(nil), options...), WithModel(model))
+			stream, err := c.ChatCompletionStream(ctx, messages, opts...)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			streams[model] = stream
+		}(model)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		for _, stream := range streams {
+			stream.Close()
+		}
+		return nil, firstErr
+	}
+
+	channels := make(map[string]<-chan StreamEvent, len(streams))
+	for model, stream := range streams {
+		events := make(chan StreamEvent)
+		channels[model] = events
+		go forwardStream(ctx, stream, events)
+	}
+	return channels, nil
+}
+
+// forwardStream sends the chunks of the stream to events until the stream ends or the
+// context is done, then closes both.
+func forwardStream(ctx context.Context, stream *ChatCompletionStream, events chan<- StreamEvent) {
+	defer close(events)
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+
+		event := StreamEvent{Chunk: chunk, Err: err}
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamCompare(t *testing.T) {
+	// The mock server streams the requested model as the content.
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body RequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model == "unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		streamHandler(
+			fmt.Sprintf("data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"%s\"}}]}\n\n", body.Model),
+			"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"!\"}, \"finish_reason\": \"stop\"}]}\n\n",
+			"data: [DONE]\n\n",
+		)(w, r)
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	models := []string{"llama3-8b-8192", "llama3-70b-8192"}
+	channels, err := c.StreamCompare(context.Background(), messages, models)
+	assert.Nil(t, err)
+	assert.Equal(t, len(models), len(channels))
+
+	for _, model := range models {
+		var content string
+		for event := range channels[model] {
+			assert.Nil(t, event.Err)
+			content += event.Chunk.Choices[0].Delta.Content
+		}
+		assert.Equal(t, model+"!", content)
+	}
+
+	_, err = c.StreamCompare(context.Background(), messages, []string{"llama3-8b-8192", "unknown"})
+	assert.NotNil(t, err)
+
+	// A duplicate model is streamed once, so that no stream is left open.
+	channels, err = c.StreamCompare(context.Background(), messages, []string{"llama3-8b-8192", "llama3-8b-8192"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(channels))
+	for range channels["llama3-8b-8192"] {
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, c.BeginShutdown(ctx))
+}
+
+func TestStreamCompareCancel(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		streamHandler("data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n")(w, r)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	channels, err := c.StreamCompare(ctx, []Message{{Role: "user", Content: "Hello, world!"}}, []string{"a", "b"})
+	assert.Nil(t, err)
+
+	for _, events := range channels {
+		event := <-events
+		assert.Equal(t, "Hi", event.Chunk.Choices[0].Delta.Content)
+	}
+	cancel()
+
+	// All channels are closed once the context is cancelled, even without draining them.
+	for _, events := range channels {
+		for range events {
+		}
+	}
+}
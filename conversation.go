@@ -0,0 +1,94 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists conversation history keyed by a conversation ID.
+type Store interface {
+	// Save replaces the stored history of the conversation with the given messages.
+	Save(id string, messages []Message) error
+	// Load returns the stored history of the conversation.
+	// It returns an empty slice and no error for an unknown conversation.
+	Load(id string) ([]Message, error)
+}
+
+// ConversationManager wraps a client and keeps the history of each conversation in a Store.
+type ConversationManager struct {
+	client *Client
+	store  Store
+}
+
+// NewConversationManager creates a new conversation manager using the given client and store.
+func NewConversationManager(client *Client, store Store) *ConversationManager {
+	return &ConversationManager{
+		client: client,
+		store:  store,
+	}
+}
+
+// Send appends messages to the history of the conversation, sends the whole history to the
+// Groq API and stores the reply of the first choice along with it.
+func (m *ConversationManager) Send(id string, messages []Message, options ...Option) (*ChatCompletionResponse, error) {
+	return m.SendWithContext(context.Background(), id, messages, options...)
+}
+
+// SendWithContext is like Send but sends the request with the given context. When the
+// request fails, e.g. because the context is cancelled, the history is left unchanged.
+func (m *ConversationManager) SendWithContext(ctx context.Context, id string, messages []Message, options ...Option) (*ChatCompletionResponse, error) {
+	history, err := m.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation := make([]Message, 0, len(history)+len(messages)+1)
+	conversation = append(conversation, history...)
+	conversation = append(conversation, messages...)
+
+	completion, err := m.client.ChatCompletionWithContext(ctx, conversation, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(completion.Choices) > 0 {
+		conversation = append(conversation, completion.Choices[0].Message)
+	}
+
+	if err := m.store.Save(id, conversation); err != nil {
+		return nil, err
+	}
+
+	return completion, nil
+}
+
+// MemoryStore is a Store that keeps conversations in memory.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	conversations map[string][]Message
+}
+
+// NewMemoryStore creates a new empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		conversations: map[string][]Message{},
+	}
+}
+
+// Save replaces the stored history of the conversation with a copy of the given messages.
+func (s *MemoryStore) Save(id string, messages []Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conversations[id] = append([]Message(nil), messages...)
+	return nil
+}
+
+// Load returns a copy of the stored history of the conversation.
+func (s *MemoryStore) Load(id string) ([]Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Message(nil), s.conversations[id]...), nil
+}
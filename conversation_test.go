@@ -0,0 +1,59 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationManager(t *testing.T) {
+	var received [][]Message
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []Message `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received = append(received, body.Messages)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi!"}, "finish_reason": "stop"}]}`))
+	})
+
+	store := NewMemoryStore()
+	m := NewConversationManager(c, store)
+
+	_, err := m.Send("chat-1", []Message{{Role: "user", Content: "Hello"}})
+	assert.Nil(t, err)
+
+	_, err = m.Send("chat-1", []Message{{Role: "user", Content: "How are you?"}})
+	assert.Nil(t, err)
+
+	// The second request carries the whole history.
+	assert.Equal(t, []Message{
+		{Role: "user", Content: "Hello"},
+		{Role: "assistant", Content: "Hi!"},
+		{Role: "user", Content: "How are you?"},
+	}, received[1])
+
+	history, err := store.Load("chat-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(history))
+	assert.Equal(t, "assistant", history[3].Role)
+
+	// Other conversations are unaffected.
+	history, err = store.Load("chat-2")
+	assert.Nil(t, err)
+	assert.Empty(t, history)
+
+	// A cancelled request leaves the history unchanged.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.SendWithContext(ctx, "chat-1", []Message{{Role: "user", Content: "Still there?"}})
+	assert.ErrorIs(t, err, context.Canceled)
+	history, err = store.Load("chat-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(history))
+}
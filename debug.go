@@ -0,0 +1,144 @@
+package groq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in debug logs.
+const redacted = "[REDACTED]"
+
+// debugTransport logs the requests and responses of a client to a writer, with the API key
+// redacted. JSON bodies are logged whole, indented if indent is set; streamed responses are
+// logged as they are read.
+type debugTransport struct {
+	next   http.RoundTripper
+	mu     sync.Mutex
+	w      io.Writer
+	indent bool
+}
+
+// newDebugTransport returns a transport that logs to w and sends the requests with next, or
+// with http.DefaultTransport when next is nil.
+func newDebugTransport(next http.RoundTripper, w io.Writer, indent bool) *debugTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &debugTransport{next: next, w: w, indent: indent}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The key is redacted anywhere in the log, not only in the Authorization header.
+	var secrets []string
+	if key := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); key != "" {
+		secrets = append(secrets, key)
+	}
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "--> %s %s\n", req.Method, req.URL)
+	writeHeaders(&entry, req.Header)
+
+	if req.Body != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		// The transport must not modify the caller's request, so the body is set on a copy.
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		t.writeBody(&entry, body)
+	} else if req.Body != nil {
+		fmt.Fprintf(&entry, "[%s body not logged]\n", req.Header.Get("Content-Type"))
+	}
+	t.log(entry.String(), secrets)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.log(fmt.Sprintf("<-- %s %s: %v\n", req.Method, req.URL, err), secrets)
+		return nil, err
+	}
+
+	entry.Reset()
+	fmt.Fprintf(&entry, "<-- %s %s\n", resp.Status, req.URL)
+	writeHeaders(&entry, resp.Header)
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.log(entry.String(), secrets)
+		resp.Body = &debugBody{ReadCloser: resp.Body, t: t, secrets: secrets}
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.writeBody(&entry, body)
+	t.log(entry.String(), secrets)
+
+	return resp, nil
+}
+
+// writeBody writes a body to a log entry, indented if it is JSON and indent is set.
+func (t *debugTransport) writeBody(entry *strings.Builder, body []byte) {
+	var indented bytes.Buffer
+	if t.indent && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	entry.Write(body)
+	entry.WriteString("\n")
+}
+
+// log writes an entry to the log with the secrets redacted.
+func (t *debugTransport) log(entry string, secrets []string) {
+	for _, secret := range secrets {
+		entry = strings.ReplaceAll(entry, secret, redacted)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = io.WriteString(t.w, entry)
+}
+
+// writeHeaders writes the headers sorted by name, with the Authorization header redacted.
+func writeHeaders(w io.Writer, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			if strings.EqualFold(name, "Authorization") {
+				value = redacted
+			}
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+}
+
+// debugBody logs a streamed response body as it is read.
+type debugBody struct {
+	io.ReadCloser
+	t       *debugTransport
+	secrets []string
+}
+
+// Read implements the io.Reader interface.
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.t.log(string(p[:n]), b.secrets)
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+package groq
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDebug(t *testing.T) {
+	const key = "gsk_secret_key_123"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server receives the real key.
+		assert.Equal(t, "Bearer "+key, r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Echo: ` + key + `"}}]}`))
+	}))
+	defer ts.Close()
+
+	var log bytes.Buffer
+	c := NewClient(WithAPIKey(key), WithBaseURL(ts.URL), WithHTTPClient(ts.Client()), WithDebug(&log))
+
+	completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "What is the key " + key + "?"}})
+
+	assert.Nil(t, err)
+	// The response is still decoded after being logged.
+	assert.Equal(t, "Echo: "+key, completion.Choices[0].Message.Content)
+
+	assert.Contains(t, log.String(), "--> POST "+ts.URL+"/chat/completions")
+	assert.Contains(t, log.String(), "Authorization: [REDACTED]")
+	assert.Contains(t, log.String(), `"content":"What is the key [REDACTED]?"`)
+	assert.Contains(t, log.String(), "<-- 200 OK")
+	assert.Contains(t, log.String(), `"content": "Echo: [REDACTED]"`)
+	assert.NotContains(t, log.String(), key)
+}
+
+func TestWithDebugIndent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"bad request"}}`))
+	}))
+	defer ts.Close()
+
+	var log bytes.Buffer
+	c := NewClient(WithBaseURL(ts.URL), WithHTTPClient(ts.Client()), WithDebug(&log), WithDebugIndent())
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, log.String(), "{\n  \"messages\": [\n    {\n      \"role\": \"user\",")
+	assert.Contains(t, log.String(), "{\n  \"error\": {\n    \"message\": \"bad request\"\n  }\n}\n")
+}
+
+func TestWithDebugStream(t *testing.T) {
+	ts := httptest.NewServer(streamHandler(
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hello!\"}}]}\n\n",
+		"data: [DONE]\n\n",
+	))
+	defer ts.Close()
+
+	var log bytes.Buffer
+	c := NewClient(WithAPIKey("test-key"), WithBaseURL(ts.URL), WithHTTPClient(ts.Client()), WithDebug(&log))
+
+	stream, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	content, _ := collect(stream)
+	stream.Close()
+
+	assert.Equal(t, "Hello!", content)
+	assert.Contains(t, log.String(), `"content": "Hello!"`)
+	assert.Contains(t, log.String(), "data: [DONE]")
+	assert.NotContains(t, log.String(), "test-key")
+}
@@ -0,0 +1,35 @@
+package groq
+
+import "encoding/json"
+
+// decodeLenient salvages what it can of a response that failed to decode: the fields are
+// decoded one by one, and those with an unexpected type are skipped. It reports false when
+// data isn't a JSON object at all.
+func decodeLenient(data []byte) (*ChatCompletionResponse, bool) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false
+	}
+
+	completion := &ChatCompletionResponse{}
+	_ = json.Unmarshal(raw["id"], &completion.ID)
+	_ = json.Unmarshal(raw["object"], &completion.Object)
+	_ = json.Unmarshal(raw["model"], &completion.Model)
+
+	var choices []map[string]json.RawMessage
+	_ = json.Unmarshal(raw["choices"], &choices)
+	for i, rawChoice := range choices {
+		choice := Choice{Index: i}
+		_ = json.Unmarshal(rawChoice["index"], &choice.Index)
+		_ = json.Unmarshal(rawChoice["finish_reason"], &choice.FinishReason)
+
+		var message map[string]json.RawMessage
+		_ = json.Unmarshal(rawChoice["message"], &message)
+		_ = json.Unmarshal(message["role"], &choice.Message.Role)
+		_ = json.Unmarshal(message["content"], &choice.Message.Content)
+
+		completion.Choices = append(completion.Choices, choice)
+	}
+
+	return completion, true
+}
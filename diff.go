@@ -0,0 +1,45 @@
+package groq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffResponses returns a readable description of the differences in content, finish reasons
+// and token usage between two responses, one line per difference. It returns an empty string
+// when there are none.
+func DiffResponses(a, b *ChatCompletionResponse) string {
+	if a == nil || b == nil {
+		if a == b {
+			return ""
+		}
+		return fmt.Sprintf("response: %s != %s\n", describeNil(a), describeNil(b))
+	}
+
+	var diff strings.Builder
+	differ := func(field string, x, y interface{}) {
+		if x != y {
+			fmt.Fprintf(&diff, "%s: %#v != %#v\n", field, x, y)
+		}
+	}
+
+	differ("len(choices)", len(a.Choices), len(b.Choices))
+	for i := 0; i < len(a.Choices) && i < len(b.Choices); i++ {
+		differ(fmt.Sprintf("choices[%d].message.content", i), a.Choices[i].Message.Content, b.Choices[i].Message.Content)
+		differ(fmt.Sprintf("choices[%d].finish_reason", i), a.Choices[i].FinishReason, b.Choices[i].FinishReason)
+	}
+
+	differ("usage.prompt_tokens", a.Usage.PromptTokens, b.Usage.PromptTokens)
+	differ("usage.completion_tokens", a.Usage.CompletionTokens, b.Usage.CompletionTokens)
+	differ("usage.total_tokens", a.Usage.TotalTokens, b.Usage.TotalTokens)
+
+	return diff.String()
+}
+
+// describeNil describes a possibly nil response for DiffResponses.
+func describeNil(r *ChatCompletionResponse) string {
+	if r == nil {
+		return "nil"
+	}
+	return "non-nil"
+}
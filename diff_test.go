@@ -0,0 +1,26 @@
+package groq
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResponses(t *testing.T) {
+	decode := func(body string) *ChatCompletionResponse {
+		r := &ChatCompletionResponse{}
+		assert.Nil(t, json.Unmarshal([]byte(body), r))
+		return r
+	}
+
+	a := decode(`{"choices": [{"message": {"content": "Hello"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 5, "completion_tokens": 1, "total_tokens": 6}}`)
+	b := decode(`{"choices": [{"message": {"content": "Hello there"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 5, "completion_tokens": 2, "total_tokens": 7}}`)
+
+	assert.Equal(t, "", DiffResponses(a, a))
+	assert.Equal(t, ""+
+		"choices[0].message.content: \"Hello\" != \"Hello there\"\n"+
+		"usage.completion_tokens: 1 != 2\n"+
+		"usage.total_tokens: 6 != 7\n", DiffResponses(a, b))
+	assert.Equal(t, "response: non-nil != nil\n", DiffResponses(a, nil))
+}
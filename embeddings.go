@@ -0,0 +1,99 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// EmbeddingInput is the input of an embedding request. A single input is sent as a string,
+// several as an array.
+type EmbeddingInput []string
+
+// MarshalJSON implements the json.Marshaler interface.
+func (in EmbeddingInput) MarshalJSON() ([]byte, error) {
+	if len(in) == 1 {
+		return json.Marshal(in[0])
+	}
+	return json.Marshal([]string(in))
+}
+
+// EmbeddingRequest represents a request to create embeddings.
+type EmbeddingRequest struct {
+	// Model specifies the embedding model to use.
+	Model string `json:"model"`
+	// Input contains the texts to embed.
+	Input EmbeddingInput `json:"input"`
+	// EncodingFormat is the format of the embeddings. Only "float" is decoded.
+	EncodingFormat string `json:"encoding_format,omitempty"`
+}
+
+// Embedding represents the embedding of a single input.
+type Embedding struct {
+	// Object specifies the type of object, i.e. "embedding".
+	Object string `json:"object"`
+	// Index is the position of the input the embedding belongs to.
+	Index int `json:"index"`
+	// Embedding is the embedding vector.
+	Embedding []float64 `json:"embedding"`
+}
+
+// EmbeddingUsage contains usage statistics for an embedding request.
+type EmbeddingUsage struct {
+	// PromptTokens is the number of tokens in the inputs.
+	PromptTokens int `json:"prompt_tokens"`
+	// TotalTokens is the total number of tokens used.
+	TotalTokens int `json:"total_tokens"`
+}
+
+// EmbeddingResponse represents the response to an embedding request.
+type EmbeddingResponse struct {
+	// Object specifies the type of object, i.e. "list".
+	Object string `json:"object"`
+	// Data contains the embeddings, in the order of the inputs.
+	Data []Embedding `json:"data"`
+	// Model specifies the model used for the embeddings.
+	Model string `json:"model"`
+	// Usage contains usage statistics for the request.
+	Usage EmbeddingUsage `json:"usage"`
+}
+
+// CreateEmbeddings creates embeddings for the inputs of the request. The embeddings of the
+// response are in the same order as the inputs.
+func (c *Client) CreateEmbeddings(ctx context.Context, req EmbeddingRequest) (*EmbeddingResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if req.Model == "" {
+		return nil, ErrModelRequired
+	}
+	if len(req.Input) == 0 {
+		return nil, errors.New("groq: at least one input is required")
+	}
+
+	if !c.inflight.begin() {
+		return nil, ErrShuttingDown
+	}
+	defer c.inflight.end()
+
+	var embeddings EmbeddingResponse
+	err := c.retry(ctx, func() error {
+		resp, err := c.postJSON(ctx, "/embeddings", req, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		embeddings = EmbeddingResponse{}
+		return json.NewDecoder(resp.Body).Decode(&embeddings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(embeddings.Data, func(i, j int) bool {
+		return embeddings.Data[i].Index < embeddings.Data[j].Index
+	})
+	return &embeddings, nil
+}
@@ -0,0 +1,55 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEmbeddings(t *testing.T) {
+	var input json.RawMessage
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+
+		var body struct {
+			Model string          `json:"model"`
+			Input json.RawMessage `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "nomic-embed-text-v1_5", body.Model)
+		input = body.Input
+
+		w.WriteHeader(http.StatusOK)
+		// The embeddings are returned out of order.
+		_, _ = w.Write([]byte(`{"object": "list", "model": "nomic-embed-text-v1_5", "data": [
+			{"object": "embedding", "index": 1, "embedding": [0.3, 0.4]},
+			{"object": "embedding", "index": 0, "embedding": [0.1, 0.2]}
+		], "usage": {"prompt_tokens": 4, "total_tokens": 4}}`))
+	})
+
+	embeddings, err := c.CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Model: "nomic-embed-text-v1_5",
+		Input: EmbeddingInput{"Hello", "world"},
+	})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `["Hello", "world"]`, string(input))
+	assert.Equal(t, 2, len(embeddings.Data))
+	assert.Equal(t, []float64{0.1, 0.2}, embeddings.Data[0].Embedding)
+	assert.Equal(t, []float64{0.3, 0.4}, embeddings.Data[1].Embedding)
+	assert.Equal(t, 4, embeddings.Usage.TotalTokens)
+
+	_, err = c.CreateEmbeddings(context.Background(), EmbeddingRequest{
+		Model: "nomic-embed-text-v1_5",
+		Input: EmbeddingInput{"Hello"},
+	})
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `"Hello"`, string(input))
+
+	_, err = c.CreateEmbeddings(context.Background(), EmbeddingRequest{Input: EmbeddingInput{"Hello"}})
+	assert.ErrorIs(t, err, ErrModelRequired)
+}
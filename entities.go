@@ -1,26 +1,143 @@
 package groq
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
 
 // Client represents a client for interacting with the Groq API.
 type Client struct {
 	// apiKey is the API key for authentication.
 	apiKey string
-	// chatCompletionURL is the endpoint for chat completions.
-	chatCompletionURL string
+	// baseURL is the URL the endpoint paths are joined with.
+	baseURL string
 	// httpClient is the HTTP client used for making requests.
 	httpClient *http.Client
+	// authRedirectHosts are the hosts the Authorization header is kept for on redirects.
+	authRedirectHosts map[string]bool
+	// userAgent is the User-Agent header sent with every request.
+	userAgent string
+	// inflight tracks the requests in flight for BeginShutdown.
+	inflight *inflight
+	// err is a configuration error returned by every request of the client.
+	err error
+	// profile holds the options applied to every request before the per-call options.
+	profile []Option
+	// modelAliases maps logical model names to concrete model IDs.
+	modelAliases map[string]string
+	// modelDefaults maps model IDs to the options applied to requests for that model.
+	modelDefaults map[string][]Option
+	// weightedModels are the models requests are spread across.
+	weightedModels []WeightedModel
+	// requireExplicitModel makes requests fail when no model is set instead of using the default.
+	requireExplicitModel bool
+	// requestIDGenerator generates the X-Request-ID header of every chat completion request.
+	requestIDGenerator func() string
+	// responseHeaderHook is called with the headers of every response of the API.
+	responseHeaderHook func(http.Header)
+	// prettyRequestBody makes requests send indented JSON.
+	prettyRequestBody bool
+	// strictValidation makes ChatCompletion validate the request body before sending it.
+	strictValidation bool
+	// headers are additional headers sent with every request.
+	headers map[string]string
+	// completionHook is called with the record of every successful completion.
+	completionHook func(context.Context, CompletionRecord)
+	// streamReconnects is the number of times a stream is reopened after a premature close.
+	streamReconnects int
+	// debug receives a log of the requests and responses of the client.
+	debug io.Writer
+	// debugIndent makes the debug log indent JSON bodies.
+	debugIndent bool
+	// strictKeyValidation makes NewClient check the format of the API key.
+	strictKeyValidation bool
+	// maxRetries is the number of times a rate limited or failed request is retried.
+	maxRetries int
+	// retryBaseDelay is the delay before the first retry, doubled for every further retry.
+	retryBaseDelay time.Duration
+	// waitForModel makes requests wait for a loading model instead of failing.
+	waitForModel bool
+	// dryRun makes requests return a DryRunError instead of being sent.
+	dryRun bool
+	// retryCallback is called before each wait for a retry.
+	retryCallback func(attempt int, err error, delay time.Duration)
+	// messagePreprocessor transforms the messages of every request before the body is built.
+	messagePreprocessor func([]Message) ([]Message, error)
+	// outputFilter is applied to the content of every choice in a response.
+	outputFilter func(string) (string, bool)
+	// modelsCache caches the list of models returned by ListModels.
+	modelsCache *modelsCache
+	// organization is sent in the organization header of every request, if set.
+	organization string
 }
 
 // Message represents a single message in the chat completion request.
 // It contains the role of the message sender (e.g., user or system) and the content of the message itself.
+// The Groq API rejects messages with empty content in some positions (e.g. an empty system message),
+// so ChatCompletion returns a *MessageError for those instead of sending them.
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+	// Name optionally identifies the participant that wrote the message, to distinguish
+	// participants with the same role, e.g. the speakers of a group chat.
+	Name string `json:"name,omitempty"`
+	// Refusal contains the refusal message when the model declines to answer.
+	// It is only set on messages returned by the API.
+	Refusal string `json:"refusal,omitempty"`
+	// ToolCalls contains the tool calls requested by the model in an assistant message.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the ID of the tool call a "tool" message is the result of.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
-// Option represents a function that modifies the requestBody.
-type Option func(*requestBody)
+// isEmpty reports whether the message has no content of any kind.
+func (m Message) isEmpty() bool {
+	return m.Role == "" && m.Content == "" && m.Refusal == "" && len(m.ToolCalls) == 0 && m.ToolCallID == ""
+}
+
+// Tool represents a tool the model may call.
+type Tool struct {
+	// Type is the type of the tool. Only "function" is supported.
+	Type string `json:"type"`
+	// Function describes the function.
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function the model may call.
+type FunctionDefinition struct {
+	// Name is the name of the function.
+	Name string `json:"name"`
+	// Description describes what the function does, for the model to decide when to call it.
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON schema of the parameters of the function. It may be any value
+	// that marshals to a schema, such as a map or a json.RawMessage.
+	Parameters interface{} `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a call of a tool requested by the model.
+type ToolCall struct {
+	// ID is the identifier of the call, to be sent back as the ToolCallID of the result.
+	ID string `json:"id,omitempty"`
+	// Type is the type of the tool, i.e. "function".
+	Type string `json:"type,omitempty"`
+	// Function contains the name and arguments of the called function.
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall represents the name and arguments of a called function.
+type FunctionCall struct {
+	// Name is the name of the function.
+	Name string `json:"name,omitempty"`
+	// Arguments are the arguments of the call, encoded as JSON.
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// Option represents a function that modifies the RequestBody.
+type Option func(*RequestBody)
 
 // ClientOption represents a function that modifies the Client.
 type ClientOption func(*Client)
@@ -32,27 +149,374 @@ func WithAPIKey(apiKey string) ClientOption {
 	}
 }
 
-type requestBody struct {
+// WithOrganization sets the organization that requests are billed to, for API keys that
+// belong to several organizations. It is sent in the Groq-Organization header of every request.
+// It defaults to the GROQ_ORG_ID environment variable.
+func WithOrganization(organization string) ClientOption {
+	return func(c *Client) {
+		c.organization = organization
+	}
+}
+
+// WithBaseURL sets the base URL of the API, e.g. a proxy or gateway that mirrors the Groq API.
+// Endpoint paths such as "/chat/completions" are joined with it.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for making requests.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAuthRedirectHosts keeps the Authorization header when a request is redirected to one of
+// the given hosts, e.g. a regional endpoint behind a geo-routing gateway. By default net/http
+// drops the header on redirects to a different domain, which makes the API reject the request.
+func WithAuthRedirectHosts(hosts ...string) ClientOption {
+	return func(c *Client) {
+		if c.authRedirectHosts == nil {
+			c.authRedirectHosts = map[string]bool{}
+		}
+		for _, host := range hosts {
+			c.authRedirectHosts[host] = true
+		}
+	}
+}
+
+// WithUserAgentSuffix appends the given suffix to the default User-Agent of the client,
+// e.g. "groq-go/0.1.0 my-app/1.2".
+func WithUserAgentSuffix(suffix string) ClientOption {
+	return func(c *Client) {
+		c.userAgent += " " + suffix
+	}
+}
+
+// WithModelAliases sets logical model names (e.g. "fast") that are resolved to concrete
+// model IDs before a request is sent. Models without an alias are sent unchanged.
+func WithModelAliases(aliases map[string]string) ClientOption {
+	return func(c *Client) {
+		c.modelAliases = make(map[string]string, len(aliases))
+		for alias, model := range aliases {
+			c.modelAliases[alias] = model
+		}
+	}
+}
+
+// WithDefaultOptions sets options that are applied to every request of the client. They
+// take precedence over the hardcoded defaults but not over the per-call options:
+// hardcoded defaults < client defaults < model defaults < per-call options.
+func WithDefaultOptions(options ...Option) ClientOption {
+	return func(c *Client) {
+		c.profile = append(c.profile, options...)
+	}
+}
+
+// WithDefaultModel sets the model used by requests that don't set one.
+func WithDefaultModel(model string) ClientOption {
+	return WithDefaultOptions(WithModel(model))
+}
+
+// WithDefaultTemperature sets the temperature used by requests that don't set one.
+func WithDefaultTemperature(temperature float64) ClientOption {
+	return WithDefaultOptions(WithTemperature(temperature))
+}
+
+// WithDefaultMaxTokens sets the maximum number of tokens used by requests that don't set one.
+func WithDefaultMaxTokens(maxTokens int) ClientOption {
+	return WithDefaultOptions(WithMaxTokens(maxTokens))
+}
+
+// WithModelDefaults registers options that are applied to every request for the given model,
+// e.g. a low temperature for a reasoning model. They take precedence over the profile of the
+// client but not over the per-call options. Aliases are resolved before the lookup.
+func WithModelDefaults(model string, options ...Option) ClientOption {
+	return func(c *Client) {
+		if c.modelDefaults == nil {
+			c.modelDefaults = map[string][]Option{}
+		}
+		c.modelDefaults[model] = append(c.modelDefaults[model], options...)
+	}
+}
+
+// WithWeightedModels spreads requests across models according to their weights, e.g. to
+// send 10% of the traffic to a new model. A model is picked for every request that doesn't
+// set one with a per-call option, overriding the profile and the default model.
+func WithWeightedModels(models []WeightedModel) ClientOption {
+	return func(c *Client) {
+		c.weightedModels = append([]WeightedModel(nil), models...)
+	}
+}
+
+// WithRequireExplicitModel makes ChatCompletion return ErrModelRequired when no model is set
+// via an option or profile, instead of silently falling back to the default model.
+func WithRequireExplicitModel() ClientOption {
+	return func(c *Client) {
+		c.requireExplicitModel = true
+	}
+}
+
+// WithRequestIDGenerator sets a function that generates an ID for every chat completion
+// request, sent in the X-Request-ID header. Errors of a request are returned as a
+// *RequestError carrying its ID, so failed calls can be correlated with server logs.
+func WithRequestIDGenerator(fn func() string) ClientOption {
+	return func(c *Client) {
+		c.requestIDGenerator = fn
+	}
+}
+
+// WithResponseHeaderHook sets a function that is called with the headers of every response
+// of the API, including unsuccessful ones, e.g. to record rate limits or request IDs. Besides
+// chat completions, it covers embeddings, transcriptions and the models endpoint.
+func WithResponseHeaderHook(fn func(http.Header)) ClientOption {
+	return func(c *Client) {
+		c.responseHeaderHook = fn
+	}
+}
+
+// WithPrettyRequestBody makes the client send indented JSON request bodies, which helps when
+// debugging through proxies that log or choke on long single-line bodies. It is off by default.
+func WithPrettyRequestBody() ClientOption {
+	return func(c *Client) {
+		c.prettyRequestBody = true
+	}
+}
+
+// WithStrictValidation makes ChatCompletion call RequestBody.Validate before sending a request,
+// returning its error instead of letting the API reject the request.
+func WithStrictValidation() ClientOption {
+	return func(c *Client) {
+		c.strictValidation = true
+	}
+}
+
+// WithDefaultHeaders sets additional headers sent with every request of the client, e.g.
+// the tenant ID required by a gateway. Authorization and Content-Type are not replaced, and
+// the headers set with WithHeaders take precedence.
+func WithDefaultHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		c.headers = mergeHeaders(c.headers, headers)
+	}
+}
+
+// WithCompletionHook sets a function that is called with the usage record of every
+// successful chat completion, streamed or not, e.g. to bill the end user of each request.
+// The context is the context of the request, and the Metadata of the record is the
+// metadata attached to it with ContextWithMetadata. For streams, it is called once the
+// stream ends or is closed, whichever comes first, with the usage if the API reported it.
+// Like failed requests, streams that fail, e.g. on an error event or because the callback
+// of ChatCompletionStreamCallback returns an error other than ErrStopStream, are not recorded.
+func WithCompletionHook(fn func(ctx context.Context, record CompletionRecord)) ClientOption {
+	return func(c *Client) {
+		c.completionHook = fn
+	}
+}
+
+// WithStreamReconnect makes streams reconnect up to maxAttempts times when the connection
+// closes before the end of the stream, instead of failing with io.ErrUnexpectedEOF. When
+// the server sent event IDs, the stream is resumed by sending the ID of the last event in
+// the Last-Event-ID header. Otherwise, or if the server ignores the header and starts over,
+// which is detected by the role it sends again with the first chunk, the request is sent
+// again with the content received so far as an assistant message, which the model
+// continues. The continuation is only seamless for the first choice.
+func WithStreamReconnect(maxAttempts int) ClientOption {
+	return func(c *Client) {
+		c.streamReconnects = maxAttempts
+	}
+}
+
+// WithDebug logs the requests of the client, with their JSON bodies, and the raw responses,
+// before they are decoded, to w. The API key is redacted from the log, both in the
+// Authorization header and anywhere else it appears. Streamed responses are logged as
+// they are read. It is meant for debugging, since the log contains the prompts.
+func WithDebug(w io.Writer) ClientOption {
+	return func(c *Client) {
+		c.debug = w
+	}
+}
+
+// WithDebugIndent makes WithDebug log the JSON bodies of requests and responses indented,
+// which is easier to read than the compact JSON sent over the wire. Streamed responses are
+// still logged as they are read. It has no effect without WithDebug.
+func WithDebugIndent() ClientOption {
+	return func(c *Client) {
+		c.debugIndent = true
+	}
+}
+
+// WithStrictKeyValidation makes NewClient check that the API key looks like a Groq key, with
+// the "gsk_" prefix and a plausible length, catching copy-paste errors before the first 401.
+// Every request of a client with a malformed key fails with an error wrapping
+// ErrInvalidAPIKey. It is opt-in since the key format may change.
+func WithStrictKeyValidation() ClientOption {
+	return func(c *Client) {
+		c.strictKeyValidation = true
+	}
+}
+
+// WithRetry makes requests that fail with a 429 or 5xx status code retry up to maxRetries
+// times. The client waits for the delay in the Retry-After header when present, and otherwise
+// backs off exponentially from baseDelay, with jitter. Other errors fail immediately, and
+// the wait ends early when the context of the request is done.
+func WithRetry(maxRetries int, baseDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// WithWaitForModel makes requests that fail because the model is still loading (a cold start)
+// wait for the estimated loading time and try again, a few times at most, instead of failing.
+// Other 503 responses are returned as usual.
+func WithWaitForModel() ClientOption {
+	return func(c *Client) {
+		c.waitForModel = true
+	}
+}
+
+// WithRetryCallback sets a callback that is called before each wait for a retry, either
+// configured by WithRetry or WithWaitForModel, with the number of the retry starting at 1,
+// the error of the failed attempt and the delay before the next attempt.
+func WithRetryCallback(fn func(attempt int, err error, delay time.Duration)) ClientOption {
+	return func(c *Client) {
+		c.retryCallback = fn
+	}
+}
+
+// WithDryRun makes the client build and validate requests as usual but return a
+// *DryRunError with the request body and its estimated token cost instead of sending them,
+// e.g. to check the requests of a pipeline in CI without paying for them.
+func WithDryRun() ClientOption {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+// WithMessagePreprocessor sets a function that transforms the messages of every chat
+// completion request before the body is built, e.g. to strip PII or truncate long messages.
+// If it returns an error, the request fails with it without being sent.
+func WithMessagePreprocessor(fn func([]Message) ([]Message, error)) ClientOption {
+	return func(c *Client) {
+		c.messagePreprocessor = fn
+	}
+}
+
+// WithOutputFilter sets a filter that is run on the content of each choice in a response.
+// The returned string replaces the content; returning false blocks the whole response
+// and ChatCompletion returns ErrContentBlocked. Streams are not filtered chunk by chunk:
+// only the response assembled by ChatCompletionStreamCallback and ChatCompletionStreamTo is.
+func WithOutputFilter(fn func(string) (string, bool)) ClientOption {
+	return func(c *Client) {
+		c.outputFilter = fn
+	}
+}
+
+// WithModelsCacheTTL makes ListModels cache the list of models for d, which rarely changes,
+// so that repeated calls, e.g. to validate models at startup, don't each hit the API.
+// Concurrent calls that miss the cache share a single request. A d of 0 or less disables
+// the cache, which is the default.
+func WithModelsCacheTTL(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.modelsCache = nil
+		if d > 0 {
+			c.modelsCache = &modelsCache{ttl: d}
+		}
+	}
+}
+
+// StreamOptions represents the options of a streamed response.
+type StreamOptions struct {
+	// IncludeUsage makes the last chunk of the stream carry the usage statistics.
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Stop is a list of sequences where the text generation stops. The API accepts up to four.
+// A single sequence is sent as a string and several as an array.
+type Stop []string
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Stop) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting both forms.
+func (s *Stop) UnmarshalJSON(data []byte) error {
+	var stop string
+	if err := json.Unmarshal(data, &stop); err == nil {
+		*s = Stop{stop}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(s))
+}
+
+// RequestBody represents the body of a chat completion request.
+type RequestBody struct {
 	// Messages represents a slice of Message structures for the chat completion request.
 	Messages []Message `json:"messages"`
 	// Model specifies the model to use for the chat completion.
 	Model string `json:"model"`
+	// Logprobs requests the log probabilities of the generated tokens.
+	Logprobs bool `json:"logprobs,omitempty"`
+	// TopLogprobs is the number of most likely tokens, between 0 and 20, returned with the log
+	// probability of each token. It is only sent when Logprobs is set.
+	TopLogprobs *int `json:"top_logprobs,omitempty"`
 	// MaxTokens sets the maximum number of tokens to generate.
 	MaxTokens int `json:"max_tokens"`
+	// FrequencyPenalty penalizes tokens by how often they already appeared, between -2 and 2.
+	// It is only sent when set.
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	// N is the number of choices to generate. It is only sent when set.
+	N int `json:"n,omitempty"`
+	// PresencePenalty penalizes tokens that already appeared, between -2 and 2.
+	// It is only sent when set.
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
 	// ResponseFormat specifies the format of the response.
 	ResponseFormat struct {
 		Type string `json:"type"`
 	} `json:"response_format,omitempty"`
-	// Seed sets the seed for the random number generator.
-	Seed int `json:"seed,omitempty"`
+	// Seed sets the seed for the random number generator. It is only sent when set, so
+	// that a seed of 0 can be requested.
+	Seed *int `json:"seed,omitempty"`
 	// Stream indicates whether to stream the response.
 	Stream bool `json:"stream"`
-	// Stop specifies the sequence where the text generation should stop.
-	Stop *string `json:"stop,omitempty"`
+	// StreamOptions sets options for a streamed response.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	// Stop specifies the sequences where the text generation should stop.
+	Stop Stop `json:"stop,omitempty"`
 	// Temperature controls randomness in the output.
 	Temperature float64 `json:"temperature"`
+	// Tools lists the tools the model may call.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice controls which tool the model calls, either "none", "auto", "required" or
+	// an object naming a function.
+	ToolChoice interface{} `json:"tool_choice,omitempty"`
 	// TopP controls the diversity of the output.
 	TopP float64 `json:"top_p"`
+
+	// tokenBudget is the total number of prompt and completion tokens allowed for the request.
+	tokenBudget int
+	// apiKey overrides the API key of the client for the request.
+	apiKey string
+	// errorFinishReasons are the finish reasons of the first choice that make the request fail.
+	errorFinishReasons []FinishReason
+	// timeout limits the duration of the request, including retries.
+	timeout time.Duration
+	// stripCodeFences removes a code fence wrapping the content of each choice.
+	stripCodeFences bool
+	// onFinish is called with the response assembled by ChatCompletionStreamCallback.
+	onFinish func(*ChatCompletionResponse)
+	// lastEventID is sent in the Last-Event-ID header to resume a stream.
+	lastEventID string
+	// headers are additional headers of the request.
+	headers map[string]string
+	// headerOverrides are headers of the request that replace any header set by the client.
+	headerOverrides map[string]string
 }
 
 // ChatCompletionResponse represents the structure of the response received from the Groq API for chat completions.
@@ -67,38 +531,107 @@ type ChatCompletionResponse struct {
 	// Model specifies the model used for the chat completion.
 	Model string `json:"model,omitempty"`
 	// Choices represents a slice of choice structures containing information about each choice.
-	Choices []struct {
-		// Index specifies the index of the choice.
-		Index int `json:"index,omitempty"`
-		// Message contains the message content of the choice.
-		Message Message `json:"message,omitempty"`
-		// Logprobs represents the log probabilities of the choice.
-		Logprobs interface{} `json:"logprobs,omitempty"`
-		// FinishReason indicates the reason why the choice was finished.
-		FinishReason string `json:"finish_reason,omitempty"`
-	} `json:"choices,omitempty"`
+	Choices []Choice `json:"choices,omitempty"`
 	// Usage contains usage statistics for the chat completion.
-	Usage struct {
-		// QueueTime specifies the time spent in the queue.
-		QueueTime float64 `json:"queue_time,omitempty"`
-		// PromptTokens indicates the number of tokens in the prompt.
-		PromptTokens int `json:"prompt_tokens,omitempty"`
-		// PromptTime specifies the time spent processing the prompt.
-		PromptTime float64 `json:"prompt_time,omitempty"`
-		// CompletionTokens indicates the number of tokens in the completion.
-		CompletionTokens int `json:"completion_tokens,omitempty"`
-		// CompletionTime specifies the time spent generating the completion.
-		CompletionTime float64 `json:"completion_time,omitempty"`
-		// TotalTokens indicates the total number of tokens processed.
-		TotalTokens int `json:"total_tokens,omitempty"`
-		// TotalTime specifies the total time spent processing the request.
-		TotalTime float64 `json:"total_time,omitempty"`
-	} `json:"usage,omitempty"`
+	Usage Usage `json:"usage,omitempty"`
 	// SystemFingerprint represents a unique identifier for the system.
 	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	// ServiceTier indicates the service tier that actually served the request, e.g. "on_demand" or "flex".
+	// It is empty when the API doesn't report it.
+	ServiceTier string `json:"service_tier,omitempty"`
 	// XGroq contains additional information about the Groq system.
 	XGroq struct {
 		// ID specifies the unique identifier for the Groq system.
 		ID string `json:"id,omitempty"`
 	} `json:"x_groq,omitempty"`
+	// RateLimit contains the rate limit information from the response headers.
+	RateLimit RateLimit `json:"-"`
+	// Latency is the wall-clock time measured by the client from sending the request to
+	// decoding the response. Compared to TotalLatency, it shows the network overhead.
+	Latency time.Duration `json:"-"`
+}
+
+// Choice represents a single choice of a chat completion.
+type Choice struct {
+	// Index specifies the index of the choice.
+	Index int `json:"index,omitempty"`
+	// Message contains the message content of the choice.
+	Message Message `json:"message,omitempty"`
+	// Delta contains the incremental message content of a streamed choice.
+	// It is only set when a streaming chunk is decoded as a regular response, in which case
+	// its content is also copied into Message.
+	Delta Message `json:"delta,omitempty"`
+	// Logprobs contains the log probabilities of the tokens of the choice, when requested.
+	Logprobs *Logprobs `json:"logprobs,omitempty"`
+	// FinishReason indicates the reason why the choice was finished.
+	FinishReason FinishReason `json:"finish_reason,omitempty"`
+}
+
+// FinishReason is the reason why the generation of a choice finished.
+type FinishReason string
+
+const (
+	// FinishReasonStop means that the model finished its reply or hit a stop sequence.
+	FinishReasonStop FinishReason = "stop"
+	// FinishReasonLength means that the maximum number of tokens was reached.
+	FinishReasonLength FinishReason = "length"
+	// FinishReasonToolCalls means that the model called tools.
+	FinishReasonToolCalls FinishReason = "tool_calls"
+	// FinishReasonContentFilter means that content was omitted by a content filter.
+	FinishReasonContentFilter FinishReason = "content_filter"
+	// FinishReasonFunctionCall means that the model called a function, in the deprecated
+	// function calling API.
+	FinishReasonFunctionCall FinishReason = "function_call"
+)
+
+// Logprobs contains the log probabilities of the tokens of a choice.
+type Logprobs struct {
+	// Content contains the log probability of each token of the content.
+	Content []TokenLogprob `json:"content"`
+}
+
+// TopLogprob is the log probability of a token.
+type TopLogprob struct {
+	// Token is the token.
+	Token string `json:"token"`
+	// Logprob is the log probability of the token.
+	Logprob float64 `json:"logprob"`
+	// Bytes is the UTF-8 encoding of the token, which may be part of a character.
+	Bytes []int `json:"bytes,omitempty"`
+}
+
+// TokenLogprob is the log probability of a generated token, along with the most likely
+// tokens at its position.
+type TokenLogprob struct {
+	TopLogprob
+	// TopLogprobs are the most likely tokens at the position, when requested.
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// TokenProb is a generated token with its probability, as returned by TopTokens.
+type TokenProb struct {
+	// Position is the index of the token in the content of the choice.
+	Position int
+	// Token is the token.
+	Token string
+	// Probability is the probability of the token, between 0 and 1.
+	Probability float64
+}
+
+// Usage represents the usage statistics of a chat completion.
+type Usage struct {
+	// QueueTime specifies the time spent in the queue.
+	QueueTime float64 `json:"queue_time,omitempty"`
+	// PromptTokens indicates the number of tokens in the prompt.
+	PromptTokens int `json:"prompt_tokens,omitempty"`
+	// PromptTime specifies the time spent processing the prompt.
+	PromptTime float64 `json:"prompt_time,omitempty"`
+	// CompletionTokens indicates the number of tokens in the completion.
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+	// CompletionTime specifies the time spent generating the completion.
+	CompletionTime float64 `json:"completion_time,omitempty"`
+	// TotalTokens indicates the total number of tokens processed.
+	TotalTokens int `json:"total_tokens,omitempty"`
+	// TotalTime specifies the total time spent processing the request.
+	TotalTime float64 `json:"total_time,omitempty"`
 }
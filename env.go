@@ -0,0 +1,74 @@
+package groq
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cleanAPIKey removes the whitespace and the matching single or double quotes around an API
+// key, which are easily exported along with it, e.g. GROQ_API_KEY="'gsk_...'", and would
+// otherwise cause a 401.
+func cleanAPIKey(key string) string {
+	key = strings.TrimSpace(key)
+	if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') && key[len(key)-1] == key[0] {
+		key = strings.TrimSpace(key[1 : len(key)-1])
+	}
+	return key
+}
+
+// WithOptionsFromEnv applies request options read from the environment as defaults for every
+// request, below the per-call options:
+//
+//   - GROQ_MODEL sets the model.
+//   - GROQ_TEMPERATURE sets the temperature.
+//   - GROQ_MAX_TOKENS sets the maximum number of tokens.
+//   - GROQ_TOP_P sets the top_p value.
+//
+// An invalid value makes every request of the client fail with an error naming the variable.
+func WithOptionsFromEnv() ClientOption {
+	return func(c *Client) {
+		options, err := optionsFromEnv()
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.profile = append(c.profile, options...)
+	}
+}
+
+// optionsFromEnv returns the request options set by GROQ_* environment variables.
+func optionsFromEnv() ([]Option, error) {
+	var options []Option
+
+	if model := os.Getenv("GROQ_MODEL"); model != "" {
+		options = append(options, WithModel(model))
+	}
+
+	if value := os.Getenv("GROQ_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("groq: invalid GROQ_TEMPERATURE %q: %w", value, err)
+		}
+		options = append(options, WithTemperature(temperature))
+	}
+
+	if value := os.Getenv("GROQ_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("groq: invalid GROQ_MAX_TOKENS %q: %w", value, err)
+		}
+		options = append(options, WithMaxTokens(maxTokens))
+	}
+
+	if value := os.Getenv("GROQ_TOP_P"); value != "" {
+		topP, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("groq: invalid GROQ_TOP_P %q: %w", value, err)
+		}
+		options = append(options, WithTopP(topP))
+	}
+
+	return options, nil
+}
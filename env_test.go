@@ -0,0 +1,80 @@
+package groq
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsFromEnv(t *testing.T) {
+	setenv := func(t *testing.T, key, value string) {
+		os.Setenv(key, value)
+		t.Cleanup(func() { os.Unsetenv(key) })
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		setenv(t, "GROQ_MODEL", "llama3-70b-8192")
+		setenv(t, "GROQ_TEMPERATURE", "0.2")
+		setenv(t, "GROQ_MAX_TOKENS", "256")
+
+		var body RequestBody
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": "123"}`))
+		}, WithOptionsFromEnv())
+
+		// Per-call options take precedence over the environment.
+		_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}}, WithMaxTokens(64))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "llama3-70b-8192", body.Model)
+		assert.Equal(t, 0.2, body.Temperature)
+		assert.Equal(t, 64, body.MaxTokens)
+		assert.Equal(t, 1.0, body.TopP)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		setenv(t, "GROQ_TEMPERATURE", "warm")
+
+		c := NewClient(WithOptionsFromEnv())
+		_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "GROQ_TEMPERATURE")
+	})
+}
+
+func TestCleanAPIKey(t *testing.T) {
+	tests := map[string]string{
+		"gsk_123":       "gsk_123",
+		" gsk_123\n":    "gsk_123",
+		`"gsk_123"`:     "gsk_123",
+		`'gsk_123'`:     "gsk_123",
+		` ' gsk_123 ' `: "gsk_123",
+		`"gsk_123'`:     `"gsk_123'`,
+		`"`:             `"`,
+		"":              "",
+	}
+	for key, expected := range tests {
+		assert.Equal(t, expected, cleanAPIKey(key), key)
+	}
+
+	os.Setenv("GROQ_API_KEY", "'gsk_123'")
+	defer os.Unsetenv("GROQ_API_KEY")
+	assert.Equal(t, "gsk_123", NewClient().apiKey)
+}
+
+func TestOrganizationFromEnv(t *testing.T) {
+	assert.Equal(t, "", NewClient().organization)
+
+	os.Setenv("GROQ_ORG_ID", " org_env\n")
+	defer os.Unsetenv("GROQ_ORG_ID")
+
+	assert.Equal(t, "org_env", NewClient().organization)
+	// An explicit organization takes precedence.
+	assert.Equal(t, "org_123", NewClient(WithOrganization("org_123")).organization)
+}
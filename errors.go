@@ -0,0 +1,180 @@
+package groq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrContentBlocked is returned when the output filter rejects the content of a response.
+var ErrContentBlocked = errors.New("groq: response blocked by output filter")
+
+// ErrModelRequired is returned when no model is set and the client requires an explicit model.
+var ErrModelRequired = errors.New("groq: no model set")
+
+// ErrInvalidAPIKey is returned by clients created with WithStrictKeyValidation whose API key
+// is malformed.
+var ErrInvalidAPIKey = errors.New("groq: invalid API key")
+
+// ErrNoMessages is returned for chat completion requests without messages.
+var ErrNoMessages = errors.New("groq: at least one message is required")
+
+// ErrTokenBudgetExceeded is returned when the prompt alone exceeds the token budget of a request.
+var ErrTokenBudgetExceeded = errors.New("groq: prompt exceeds token budget")
+
+// ErrJSONNotMentioned is returned for JSON mode requests whose messages don't mention JSON,
+// which the API rejects.
+var ErrJSONNotMentioned = errors.New("groq: JSON mode requires the word \"json\" in the messages")
+
+// ErrStreamNotSupported is returned when a request body with Stream set is sent by a
+// non-streaming method.
+var ErrStreamNotSupported = errors.New("groq: stream is set on a non-streaming request, use ChatCompletionStream instead")
+
+// ErrStopStream can be returned by the callback of ChatCompletionStreamCallback to stop the
+// stream early without failing.
+var ErrStopStream = errors.New("groq: stream stopped")
+
+// ErrStreamClosed is returned by Recv once the stream is closed.
+var ErrStreamClosed = errors.New("groq: stream closed")
+
+// ErrShuttingDown is returned for requests made after BeginShutdown was called.
+var ErrShuttingDown = errors.New("groq: client is shutting down")
+
+// RequestError wraps the error of a request that was sent with a request ID.
+type RequestError struct {
+	// RequestID is the ID sent in the X-Request-ID header.
+	RequestID string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("groq: request %s: %v", e.RequestID, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// FinishReasonError is returned when a response finished for a reason set with
+// WithErrorOnFinishReasons.
+type FinishReasonError struct {
+	// FinishReason is the finish reason of the first choice.
+	FinishReason FinishReason
+	// Response is the response that was rejected.
+	Response *ChatCompletionResponse
+}
+
+// Error implements the error interface.
+func (e *FinishReasonError) Error() string {
+	return fmt.Sprintf("groq: unexpected finish reason: %s", e.FinishReason)
+}
+
+// PartialResponseError is returned when a response is valid JSON but doesn't have the
+// expected shape, e.g. because the API changed the type of a field. Response contains what
+// could be salvaged from it: the ID, model, and the role, content and finish reason of
+// each choice.
+type PartialResponseError struct {
+	// Err is the error of the regular decode.
+	Err error
+	// Response is the partially decoded response.
+	Response *ChatCompletionResponse
+}
+
+// Error implements the error interface.
+func (e *PartialResponseError) Error() string {
+	return fmt.Sprintf("groq: response partially decoded: %v", e.Err)
+}
+
+// Unwrap returns the error of the regular decode.
+func (e *PartialResponseError) Unwrap() error {
+	return e.Err
+}
+
+// DryRunError is returned instead of sending a request when the client is created with
+// WithDryRun.
+type DryRunError struct {
+	// Body is the request body that would have been sent.
+	Body RequestBody
+	// PromptTokens is the estimated number of prompt tokens of the request.
+	PromptTokens int
+	// Response is a synthetic response with an empty message and the estimated usage, for
+	// code paths that need one.
+	Response *ChatCompletionResponse
+}
+
+// Error implements the error interface.
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("groq: dry run of %s with an estimated %d prompt tokens", e.Body.Model, e.PromptTokens)
+}
+
+// APIError is returned when the Groq API responds with an unexpected status code.
+// The fields other than StatusCode are taken from the error body of the response and are
+// empty when the body isn't a valid API error.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response. It is 0 for an error sent as an
+	// event of a stream that had started successfully.
+	StatusCode int `json:"-"`
+	// Code is the machine-readable error code, e.g. "model_not_found".
+	Code string `json:"code"`
+	// Message is the human-readable error message.
+	Message string `json:"message"`
+	// Type is the error type, e.g. "invalid_request_error".
+	Type string `json:"type"`
+	// EstimatedTime is the estimated number of seconds until the model is loaded.
+	// It is only set for model loading errors that report it.
+	EstimatedTime float64 `json:"estimated_time,omitempty"`
+
+	// retryAfter is the delay requested by the Retry-After header of the response.
+	retryAfter time.Duration
+}
+
+// IsModelLoading reports whether the error means that the model is still being loaded
+// (a cold start), as opposed to any other unavailability of the service.
+func (e *APIError) IsModelLoading() bool {
+	if e.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	return e.Code == "model_loading" || strings.Contains(strings.ToLower(e.Message), "loading")
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("stream error: %s", e.Message)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, e.Message)
+}
+
+// maxErrorBodySize is the maximum number of bytes of an error body that are read.
+const maxErrorBodySize = 1 << 20
+
+// newAPIError builds an APIError from an unsuccessful response.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	var body struct {
+		Error         *APIError `json:"error"`
+		EstimatedTime float64   `json:"estimated_time"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err == nil && json.Unmarshal(data, &body) == nil && body.Error != nil {
+		body.Error.StatusCode = resp.StatusCode
+		if body.Error.EstimatedTime == 0 {
+			body.Error.EstimatedTime = body.EstimatedTime
+		}
+		apiErr = body.Error
+	}
+
+	apiErr.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return apiErr
+}
@@ -0,0 +1,23 @@
+package groq
+
+// Example is an input and the expected output, for few-shot prompting.
+type Example struct {
+	// Input is the content of the user message of the example.
+	Input string
+	// Output is the content of the assistant reply of the example.
+	Output string
+}
+
+// FewShot returns the messages of a few-shot prompt: a user message and an assistant reply
+// for each example, in order, followed by the user message with the query. Prepend a system
+// message to the result to describe the task.
+func FewShot(examples []Example, query string) []Message {
+	messages := make([]Message, 0, 2*len(examples)+1)
+	for _, example := range examples {
+		messages = append(messages,
+			Message{Role: "user", Content: example.Input},
+			Message{Role: "assistant", Content: example.Output},
+		)
+	}
+	return append(messages, Message{Role: "user", Content: query})
+}
@@ -0,0 +1,24 @@
+package groq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFewShot(t *testing.T) {
+	messages := FewShot([]Example{
+		{Input: "I loved it!", Output: "positive"},
+		{Input: "Never again.", Output: "negative"},
+	}, "It was fine.")
+
+	assert.Equal(t, []Message{
+		{Role: "user", Content: "I loved it!"},
+		{Role: "assistant", Content: "positive"},
+		{Role: "user", Content: "Never again."},
+		{Role: "assistant", Content: "negative"},
+		{Role: "user", Content: "It was fine."},
+	}, messages)
+
+	assert.Equal(t, []Message{{Role: "user", Content: "It was fine."}}, FewShot(nil, "It was fine."))
+}
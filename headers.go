@@ -0,0 +1,36 @@
+package groq
+
+import "net/http"
+
+// organizationHeader is the header that selects the organization a request is billed to.
+const organizationHeader = "Groq-Organization"
+
+// protectedHeaders are the headers set by the client that WithHeaders and WithDefaultHeaders
+// don't replace, so that a header map can't break authentication or the encoding of the
+// body by accident. WithHeaderOverride replaces them.
+var protectedHeaders = map[string]bool{
+	"Authorization": true,
+	"Content-Type":  true,
+}
+
+// addHeaders sets the headers on dst, except for the protected ones.
+func addHeaders(dst http.Header, headers map[string]string) {
+	for name, value := range headers {
+		if protectedHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		dst.Set(name, value)
+	}
+}
+
+// mergeHeaders returns a copy of headers with extra added, replacing existing values.
+func mergeHeaders(headers, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(headers)+len(extra))
+	for name, value := range headers {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range extra {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+	return merged
+}
@@ -0,0 +1,48 @@
+package groq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	var header http.Header
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123", "data": []}`))
+	}, WithDefaultHeaders(map[string]string{
+		"X-Tenant-ID":   "tenant-1",
+		"x-trace-id":    "client",
+		"Authorization": "Bearer stolen",
+	}))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	_, err := c.ChatCompletion(messages, WithHeaders(map[string]string{
+		"X-Trace-ID":   "request",
+		"Content-Type": "text/plain",
+	}))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-1", header.Get("X-Tenant-ID"))
+	assert.Equal(t, "request", header.Get("X-Trace-ID"))
+	// Protected headers are not replaced by accident.
+	assert.Equal(t, "Bearer test-key", header.Get("Authorization"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	_, err = c.ChatCompletion(messages, WithHeaderOverride("Authorization", "Bearer gateway-key"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer gateway-key", header.Get("Authorization"))
+	assert.Equal(t, "client", header.Get("X-Trace-ID"))
+
+	// The client headers are sent with every request.
+	_, err = c.ListModels(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "tenant-1", header.Get("X-Tenant-ID"))
+	assert.Equal(t, "Bearer test-key", header.Get("Authorization"))
+}
@@ -0,0 +1,44 @@
+package groq
+
+import "context"
+
+// CompletionRecord is the usage record of a successful chat completion, passed to the hook
+// set with WithCompletionHook.
+type CompletionRecord struct {
+	// Model is the model requested, after aliases are resolved.
+	Model string
+	// ModelServed is the model that served the request, as reported in the response.
+	ModelServed string
+	// RequestID is the ID sent in the X-Request-ID header, if any.
+	RequestID string
+	// Stream indicates whether the completion was streamed.
+	Stream bool
+	// Usage contains the usage statistics of the completion. It is zero for a stream whose
+	// usage wasn't reported.
+	Usage Usage
+	// Metadata is the metadata attached to the context of the request with
+	// ContextWithMetadata, e.g. the ID of the end user.
+	Metadata map[string]string
+}
+
+// metadataKey is the context key of the metadata of a request.
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of the context with the given key and value added to
+// the metadata passed to the completion hook, e.g. the ID of the end user of a request.
+func ContextWithMetadata(ctx context.Context, key, value string) context.Context {
+	parent := MetadataFromContext(ctx)
+	metadata := make(map[string]string, len(parent)+1)
+	for k, v := range parent {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// MetadataFromContext returns the metadata attached to the context with
+// ContextWithMetadata, or nil if there is none. The map must not be modified.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
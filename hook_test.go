@@ -0,0 +1,100 @@
+package groq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionHook(t *testing.T) {
+	var records []CompletionRecord
+	hook := WithCompletionHook(func(ctx context.Context, record CompletionRecord) {
+		records = append(records, record)
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+	ctx := ContextWithMetadata(ContextWithMetadata(context.Background(), "user_id", "u-42"), "team", "search")
+
+	c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123", "model": "llama-3.1-8b-instant", "usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15}}`), hook, WithModelAliases(map[string]string{"fast": "llama-3.1-8b-instant"}))
+
+	_, err := c.ChatCompletionWithContext(ctx, messages, WithModel("fast"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []CompletionRecord{{
+		Model:       "llama-3.1-8b-instant",
+		ModelServed: "llama-3.1-8b-instant",
+		Usage:       Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		Metadata:    map[string]string{"user_id": "u-42", "team": "search"},
+	}}, records)
+
+	// Failed requests are not recorded.
+	records = nil
+	c = newTestClient(t, respondWith(http.StatusInternalServerError, ``), hook)
+	_, err = c.ChatCompletionWithContext(ctx, messages)
+	assert.NotNil(t, err)
+	assert.Nil(t, records)
+}
+
+func TestCompletionHookStream(t *testing.T) {
+	var records []CompletionRecord
+	c := newTestClient(t, streamHandler(
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [], \"x_groq\": {\"usage\": {\"total_tokens\": 7}}}\n\n",
+		"data: [DONE]\n\n",
+	), WithCompletionHook(func(ctx context.Context, record CompletionRecord) {
+		records = append(records, record)
+	}))
+
+	stream, err := c.ChatCompletionStream(ContextWithMetadata(context.Background(), "user_id", "u-42"), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	defer stream.Close()
+
+	_, _ = collect(stream)
+
+	assert.Equal(t, 1, len(records))
+	assert.True(t, records[0].Stream)
+	assert.Equal(t, "llama3-8b-8192", records[0].ModelServed)
+	assert.Equal(t, 7, records[0].Usage.TotalTokens)
+	assert.Equal(t, "u-42", records[0].Metadata["user_id"])
+
+	// Closing the stream after its end doesn't record it again.
+	stream.Close()
+	assert.Equal(t, 1, len(records))
+
+	// A stream stopped early is recorded when it is closed.
+	records = nil
+	_, err = c.ChatCompletionStreamCallback(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}}, func(chunk *ChatCompletionStreamResponse) error {
+		return ErrStopStream
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "llama3-8b-8192", records[0].ModelServed)
+	assert.Equal(t, 0, records[0].Usage.TotalTokens)
+
+	// A stream that fails is not recorded.
+	records = nil
+	c = newTestClient(t, streamHandler(
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n",
+		"data: {\"error\": {\"message\": \"boom\", \"type\": \"internal_server_error\"}}\n\n",
+	), WithCompletionHook(func(ctx context.Context, record CompletionRecord) {
+		records = append(records, record)
+	}))
+	failed, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	_, err = collect(failed)
+	assert.NotNil(t, err)
+	failed.Close()
+	assert.Nil(t, records)
+}
+
+func TestContextWithMetadata(t *testing.T) {
+	assert.Nil(t, MetadataFromContext(context.Background()))
+
+	parent := ContextWithMetadata(context.Background(), "user_id", "u-1")
+	child := ContextWithMetadata(parent, "user_id", "u-2")
+
+	// Adding metadata doesn't change the parent context.
+	assert.Equal(t, "u-1", MetadataFromContext(parent)["user_id"])
+	assert.Equal(t, "u-2", MetadataFromContext(child)["user_id"])
+}
@@ -0,0 +1,169 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Model represents a model available to the API key.
+type Model struct {
+	// ID is the identifier of the model, as used in requests.
+	ID string `json:"id"`
+	// Object specifies the type of object, i.e. "model".
+	Object string `json:"object"`
+	// Created indicates the timestamp when the model was created.
+	Created int `json:"created"`
+	// OwnedBy is the organization that owns the model.
+	OwnedBy string `json:"owned_by"`
+	// Active indicates whether the model is currently available.
+	Active bool `json:"active"`
+	// ContextWindow is the maximum number of tokens the model can process.
+	ContextWindow int `json:"context_window"`
+}
+
+// ListModels returns the models currently available to the API key. With WithModelsCacheTTL,
+// the list is cached and concurrent calls that miss the cache share a single request.
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if c.modelsCache != nil {
+		return c.modelsCache.get(ctx, c.fetchModels)
+	}
+	return c.fetchModels(ctx)
+}
+
+// fetchModels requests the list of models from the API.
+func (c *Client) fetchModels(ctx context.Context) ([]Model, error) {
+
+	if !c.inflight.begin() {
+		return nil, ErrShuttingDown
+	}
+	defer c.inflight.end()
+
+	resp, err := c.get(ctx, "/models")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Data []Model `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	return list.Data, nil
+}
+
+// modelsCache caches the list of models for a time to live.
+type modelsCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	models    []Model
+	fetchedAt time.Time
+	// fetch is the request in progress, shared by the calls that miss the cache meanwhile.
+	fetch *modelsFetch
+}
+
+// modelsFetch is a request for the list of models, done once it is closed.
+type modelsFetch struct {
+	done   chan struct{}
+	models []Model
+	err    error
+}
+
+// get returns the cached models, or fetches them if the cache is empty or expired. Only one
+// fetch runs at a time; the calls made meanwhile wait for its result or for their context.
+// Errors are not cached.
+func (m *modelsCache) get(ctx context.Context, fetch func(context.Context) ([]Model, error)) ([]Model, error) {
+	m.mu.Lock()
+	if m.models != nil && time.Since(m.fetchedAt) < m.ttl {
+		models := m.models
+		m.mu.Unlock()
+		return append([]Model(nil), models...), nil
+	}
+
+	if f := m.fetch; f != nil {
+		m.mu.Unlock()
+		select {
+		case <-f.done:
+			return append([]Model(nil), f.models...), f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	f := &modelsFetch{done: make(chan struct{})}
+	m.fetch = f
+	m.mu.Unlock()
+
+	f.models, f.err = fetch(ctx)
+
+	m.mu.Lock()
+	m.fetch = nil
+	if f.err == nil {
+		m.models = f.models
+		m.fetchedAt = time.Now()
+	}
+	m.mu.Unlock()
+	close(f.done)
+
+	return append([]Model(nil), f.models...), f.err
+}
+
+// modelLimits holds the token limits of a model.
+type modelLimits struct {
+	contextWindow   int
+	maxOutputTokens int
+}
+
+var (
+	modelLimitsMu sync.RWMutex
+	// knownModelLimits maps model IDs to the limits documented by Groq.
+	knownModelLimits = map[string]modelLimits{
+		"llama3-8b-8192":                        {contextWindow: 8192, maxOutputTokens: 8192},
+		"llama3-70b-8192":                       {contextWindow: 8192, maxOutputTokens: 8192},
+		"llama-3.1-8b-instant":                  {contextWindow: 131072, maxOutputTokens: 8192},
+		"llama-3.1-70b-versatile":               {contextWindow: 131072, maxOutputTokens: 8000},
+		"llama3-groq-8b-8192-tool-use-preview":  {contextWindow: 8192, maxOutputTokens: 8192},
+		"llama3-groq-70b-8192-tool-use-preview": {contextWindow: 8192, maxOutputTokens: 8192},
+		"mixtral-8x7b-32768":                    {contextWindow: 32768, maxOutputTokens: 32768},
+		"gemma-7b-it":                           {contextWindow: 8192, maxOutputTokens: 8192},
+		"gemma2-9b-it":                          {contextWindow: 8192, maxOutputTokens: 8192},
+	}
+)
+
+// ModelContextWindow returns the context window, in tokens, of the given model.
+// It reports false when the model is unknown.
+func ModelContextWindow(model string) (int, bool) {
+	modelLimitsMu.RLock()
+	defer modelLimitsMu.RUnlock()
+
+	limits, ok := knownModelLimits[model]
+	return limits.contextWindow, ok
+}
+
+// ModelMaxOutputTokens returns the maximum number of tokens the given model can generate.
+// It reports false when the model is unknown.
+func ModelMaxOutputTokens(model string) (int, bool) {
+	modelLimitsMu.RLock()
+	defer modelLimitsMu.RUnlock()
+
+	limits, ok := knownModelLimits[model]
+	return limits.maxOutputTokens, ok
+}
+
+// RegisterModel sets the context window and output limit of a model, overriding the shipped
+// data. It is meant for custom or self-hosted models and for models added after this release.
+func RegisterModel(model string, contextWindow, maxOutputTokens int) {
+	modelLimitsMu.Lock()
+	defer modelLimitsMu.Unlock()
+
+	knownModelLimits[model] = modelLimits{contextWindow: contextWindow, maxOutputTokens: maxOutputTokens}
+}
@@ -0,0 +1,99 @@
+package groq
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelContextWindow(t *testing.T) {
+	contextWindow, ok := ModelContextWindow("llama3-8b-8192")
+	assert.True(t, ok)
+	assert.Equal(t, 8192, contextWindow)
+
+	_, ok = ModelContextWindow("my-custom-model")
+	assert.False(t, ok)
+
+	RegisterModel("my-custom-model", 4096, 1024)
+	defer func() {
+		modelLimitsMu.Lock()
+		delete(knownModelLimits, "my-custom-model")
+		modelLimitsMu.Unlock()
+	}()
+
+	contextWindow, ok = ModelContextWindow("my-custom-model")
+	assert.True(t, ok)
+	assert.Equal(t, 4096, contextWindow)
+
+	maxOutputTokens, ok := ModelMaxOutputTokens("my-custom-model")
+	assert.True(t, ok)
+	assert.Equal(t, 1024, maxOutputTokens)
+}
+
+func TestListModels(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var path string
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "llama3-8b-8192", "object": "model", "created": 1693721698, "owned_by": "Meta", "active": true, "context_window": 8192}, {"id": "whisper-large-v3", "object": "model", "created": 1693721698, "owned_by": "OpenAI", "active": true, "context_window": 448}]}`))
+		})
+
+		models, err := c.ListModels(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/models", path)
+		assert.Equal(t, 2, len(models))
+		assert.Equal(t, Model{ID: "llama3-8b-8192", Object: "model", Created: 1693721698, OwnedBy: "Meta", Active: true, ContextWindow: 8192}, models[0])
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusUnauthorized, `{"error": {"message": "Invalid API Key"}}`))
+
+		_, err := c.ListModels(context.Background())
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		var requests int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"object": "list", "data": [{"id": "llama3-8b-8192", "object": "model"}]}`))
+		}, WithModelsCacheTTL(200*time.Millisecond))
+
+		// Concurrent misses share one request.
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				models, err := c.ListModels(context.Background())
+				assert.Nil(t, err)
+				assert.Equal(t, 1, len(models))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+		// The callers get copies of the cached list.
+		models, err := c.ListModels(context.Background())
+		assert.Nil(t, err)
+		models[0].ID = "changed"
+		models, _ = c.ListModels(context.Background())
+		assert.Equal(t, "llama3-8b-8192", models[0].ID)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+		time.Sleep(200 * time.Millisecond)
+		_, err = c.ListModels(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+}
@@ -0,0 +1,53 @@
+package groq
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit represents the rate limit information returned in the headers of a response.
+type RateLimit struct {
+	// Present indicates whether the response carried any rate limit headers.
+	Present bool
+	// LimitRequests is the maximum number of requests per day.
+	LimitRequests int
+	// LimitTokens is the maximum number of tokens per minute.
+	LimitTokens int
+	// RemainingRequests is the number of requests left for the day.
+	RemainingRequests int
+	// RemainingTokens is the number of tokens left for the minute.
+	RemainingTokens int
+	// ResetRequests is the time until the request limit resets.
+	ResetRequests time.Duration
+	// ResetTokens is the time until the token limit resets.
+	ResetTokens time.Duration
+}
+
+// parseRateLimit parses the x-ratelimit-* headers of a response. Missing or invalid headers
+// are left as zero values.
+func parseRateLimit(header http.Header) RateLimit {
+	rl := RateLimit{}
+
+	parseInt := func(name string, value *int) {
+		if v := header.Get(name); v != "" {
+			rl.Present = true
+			*value, _ = strconv.Atoi(v)
+		}
+	}
+	parseDuration := func(name string, value *time.Duration) {
+		if v := header.Get(name); v != "" {
+			rl.Present = true
+			*value, _ = time.ParseDuration(v)
+		}
+	}
+
+	parseInt("x-ratelimit-limit-requests", &rl.LimitRequests)
+	parseInt("x-ratelimit-limit-tokens", &rl.LimitTokens)
+	parseInt("x-ratelimit-remaining-requests", &rl.RemainingRequests)
+	parseInt("x-ratelimit-remaining-tokens", &rl.RemainingTokens)
+	parseDuration("x-ratelimit-reset-requests", &rl.ResetRequests)
+	parseDuration("x-ratelimit-reset-tokens", &rl.ResetTokens)
+
+	return rl
+}
@@ -0,0 +1,46 @@
+package groq
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Present", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("x-ratelimit-limit-requests", "14400")
+			w.Header().Set("x-ratelimit-limit-tokens", "18000")
+			w.Header().Set("x-ratelimit-remaining-requests", "14370")
+			w.Header().Set("x-ratelimit-remaining-tokens", "17997")
+			w.Header().Set("x-ratelimit-reset-requests", "2m59.56s")
+			w.Header().Set("x-ratelimit-reset-tokens", "7.66s")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": "123"}`))
+		})
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RateLimit{
+			Present:           true,
+			LimitRequests:     14400,
+			LimitTokens:       18000,
+			RemainingRequests: 14370,
+			RemainingTokens:   17997,
+			ResetRequests:     2*time.Minute + 59560*time.Millisecond,
+			ResetTokens:       7660 * time.Millisecond,
+		}, completion.RateLimit)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusOK, `{"id": "123"}`))
+
+		completion, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RateLimit{}, completion.RateLimit)
+	})
+}
@@ -0,0 +1,30 @@
+package groq
+
+import (
+	"errors"
+	"net/http"
+)
+
+// maxRedirects is the number of redirects followed before giving up, as in net/http.
+const maxRedirects = 10
+
+// authRedirectPolicy returns a redirect policy that keeps the Authorization header on
+// redirects to the given hosts. net/http drops it on redirects to a different domain.
+// The header is never re-attached when a redirect downgrades from https to http.
+func authRedirectPolicy(hosts map[string]bool, next func(*http.Request, []*http.Request) error) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if next != nil {
+			if err := next(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= maxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		first := via[0]
+		if hosts[req.URL.Hostname()] && (first.URL.Scheme != "https" || req.URL.Scheme == "https") {
+			req.Header.Set("Authorization", first.Header.Get("Authorization"))
+		}
+		return nil
+	}
+}
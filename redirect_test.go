@@ -0,0 +1,51 @@
+package groq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRedirectHosts(t *testing.T) {
+	var authorization string
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	}))
+	defer regional.Close()
+
+	// Redirect to "localhost" rather than 127.0.0.1 so net/http treats it as a different domain.
+	regionalURL, _ := url.Parse(regional.URL)
+	regionalURL.Host = "localhost:" + regionalURL.Port()
+
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, regionalURL.String(), http.StatusTemporaryRedirect)
+	}))
+	defer gateway.Close()
+
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Dropped", func(t *testing.T) {
+		c := NewClient(WithAPIKey("test-key"))
+		c.baseURL = gateway.URL
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "", authorization)
+	})
+
+	t.Run("Kept", func(t *testing.T) {
+		c := NewClient(WithAPIKey("test-key"), WithAuthRedirectHosts("localhost"))
+		c.baseURL = gateway.URL
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer test-key", authorization)
+	})
+}
@@ -0,0 +1,92 @@
+package groq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// TokensPerSecond returns the completion throughput reported by the API, i.e. the number
+// of completion tokens divided by the completion time. It returns 0 when no timing is available.
+// ChatCompletionStream.TokensPerSecond is the equivalent measured on a stream.
+func (r *ChatCompletionResponse) TokensPerSecond() float64 {
+	if r.Usage.CompletionTime <= 0 {
+		return 0
+	}
+	return float64(r.Usage.CompletionTokens) / r.Usage.CompletionTime
+}
+
+// TotalLatency returns the total time reported by the API, including the queue time.
+// It returns 0 when no timing is available.
+func (r *ChatCompletionResponse) TotalLatency() time.Duration {
+	return time.Duration(r.Usage.TotalTime * float64(time.Second))
+}
+
+// IsRefusal reports whether the model refused to answer in any of the choices,
+// as indicated by the refusal field of the message.
+func (r *ChatCompletionResponse) IsRefusal() bool {
+	for _, choice := range r.Choices {
+		if choice.Message.Refusal != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelServed returns the model that actually served the request, as reported in the
+// response. It may differ from the requested model, e.g. when an alias is resolved or a
+// model is transparently upgraded by the backend.
+func (r *ChatCompletionResponse) ModelServed() string {
+	return r.Model
+}
+
+// SystemFingerprintChanged reports whether the backend configuration changed between a
+// previous response and this one, as indicated by their system fingerprints. It reports
+// false when either fingerprint is missing, since no change can be detected then.
+func (r *ChatCompletionResponse) SystemFingerprintChanged(previous *ChatCompletionResponse) bool {
+	if previous == nil || r.SystemFingerprint == "" || previous.SystemFingerprint == "" {
+		return false
+	}
+	return r.SystemFingerprint != previous.SystemFingerprint
+}
+
+// FirstContent returns the content of the message of the first choice, or an error when
+// the response contains no choices.
+func (r *ChatCompletionResponse) FirstContent() (string, error) {
+	if len(r.Choices) == 0 {
+		return "", errors.New("groq: response contains no choices")
+	}
+	return r.Choices[0].Message.Content, nil
+}
+
+// EnsureChoices returns an error unless the response contains exactly n choices.
+// Some models silently return fewer choices than requested.
+func (r *ChatCompletionResponse) EnsureChoices(n int) error {
+	if len(r.Choices) != n {
+		return fmt.Errorf("groq: expected %d choices, got %d", n, len(r.Choices))
+	}
+	return nil
+}
+
+// TopTokens returns the token generated at each position of the given choice with its
+// probability, ranked from the most to the least likely, e.g. to highlight the tokens the
+// model was unsure of. It returns nil when the choice doesn't exist or has no logprobs,
+// which are only returned when requested with WithLogprobs.
+func (r *ChatCompletionResponse) TopTokens(choiceIndex int) []TokenProb {
+	if choiceIndex < 0 || choiceIndex >= len(r.Choices) || r.Choices[choiceIndex].Logprobs == nil {
+		return nil
+	}
+
+	content := r.Choices[choiceIndex].Logprobs.Content
+	tokens := make([]TokenProb, len(content))
+	for i, token := range content {
+		tokens[i] = TokenProb{Position: i, Token: token.Token, Probability: math.Exp(token.Logprob)}
+	}
+	// Tokens that are equally likely stay in the order of the content.
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Probability > tokens[j].Probability
+	})
+	return tokens
+}
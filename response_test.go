@@ -0,0 +1,117 @@
+package groq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokensPerSecond(t *testing.T) {
+	r := &ChatCompletionResponse{}
+	assert.Equal(t, 0.0, r.TokensPerSecond())
+
+	r.Usage.CompletionTokens = 100
+	r.Usage.CompletionTime = 0.25
+	assert.Equal(t, 400.0, r.TokensPerSecond())
+}
+
+func TestTotalLatency(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"usage": {"queue_time": 0.25, "total_time": 1.5}}`), &r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1500*time.Millisecond, r.TotalLatency())
+	assert.Equal(t, time.Duration(0), (&ChatCompletionResponse{}).TotalLatency())
+}
+
+func TestIsRefusal(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Sure!"}}]}`), &r)
+	assert.Nil(t, err)
+	assert.False(t, r.IsRefusal())
+
+	err = json.Unmarshal([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "", "refusal": "I can't help with that."}}]}`), &r)
+	assert.Nil(t, err)
+	assert.True(t, r.IsRefusal())
+}
+
+func TestModelServed(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"model": "llama-3.1-8b-instant"}`), &r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "llama-3.1-8b-instant", r.ModelServed())
+}
+
+func TestEnsureChoices(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"choices": [{"index": 0}, {"index": 1}]}`), &r)
+	assert.Nil(t, err)
+
+	assert.Nil(t, r.EnsureChoices(2))
+	assert.NotNil(t, r.EnsureChoices(3))
+}
+
+func TestSystemFingerprintChanged(t *testing.T) {
+	a := &ChatCompletionResponse{SystemFingerprint: "fp_1"}
+	b := &ChatCompletionResponse{SystemFingerprint: "fp_2"}
+
+	assert.False(t, a.SystemFingerprintChanged(&ChatCompletionResponse{SystemFingerprint: "fp_1"}))
+	assert.True(t, b.SystemFingerprintChanged(a))
+	assert.False(t, b.SystemFingerprintChanged(&ChatCompletionResponse{}))
+	assert.False(t, b.SystemFingerprintChanged(nil))
+}
+
+func TestFirstContent(t *testing.T) {
+	var r ChatCompletionResponse
+	_, err := r.FirstContent()
+	assert.NotNil(t, err)
+
+	err = json.Unmarshal([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello!"}}, {"index": 1, "message": {"role": "assistant", "content": "Hi!"}}]}`), &r)
+	assert.Nil(t, err)
+
+	content, err := r.FirstContent()
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello!", content)
+}
+
+func TestLogprobs(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop", "logprobs": {"content": [
+		{"token": "Hi", "logprob": -0.01, "bytes": [72, 105], "top_logprobs": [{"token": "Hi", "logprob": -0.01}, {"token": "Hello", "logprob": -4.6}]}
+	]}}]}`), &r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, FinishReasonStop, r.Choices[0].FinishReason)
+	tokens := r.Choices[0].Logprobs.Content
+	assert.Equal(t, 1, len(tokens))
+	assert.Equal(t, "Hi", tokens[0].Token)
+	assert.Equal(t, -0.01, tokens[0].Logprob)
+	assert.Equal(t, []int{72, 105}, tokens[0].Bytes)
+	assert.Equal(t, "Hello", tokens[0].TopLogprobs[1].Token)
+
+	// Responses without logprobs still decode.
+	err = json.Unmarshal([]byte(`{"choices": [{"index": 0, "logprobs": null}]}`), &r)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Choices[0].Logprobs)
+}
+
+func TestTopTokens(t *testing.T) {
+	var r ChatCompletionResponse
+	err := json.Unmarshal([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hi there"}, "logprobs": {"content": [
+		{"token": "Hi", "logprob": -0.5},
+		{"token": " there", "logprob": 0}
+	]}}, {"index": 1, "message": {"role": "assistant", "content": "Hello"}}]}`), &r)
+	assert.Nil(t, err)
+
+	tokens := r.TopTokens(0)
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, TokenProb{Position: 1, Token: " there", Probability: 1}, tokens[0])
+	assert.Equal(t, 0, tokens[1].Position)
+	assert.InDelta(t, 0.6065, tokens[1].Probability, 0.0001)
+
+	assert.Nil(t, r.TopTokens(1))
+	assert.Nil(t, r.TopTokens(2))
+}
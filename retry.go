@@ -0,0 +1,115 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxModelLoadingWaits is the number of times a request waits for a loading model.
+const maxModelLoadingWaits = 5
+
+// defaultModelLoadingDelay is the time waited for a loading model that reports no estimate.
+const defaultModelLoadingDelay = 5 * time.Second
+
+// modelLoadingDelay returns how long to wait before retrying a request that failed with err,
+// and reports whether err is a model loading error at all.
+func modelLoadingDelay(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsModelLoading() {
+		return 0, false
+	}
+	if apiErr.EstimatedTime <= 0 {
+		return defaultModelLoadingDelay, true
+	}
+	return time.Duration(apiErr.EstimatedTime * float64(time.Second)), true
+}
+
+// sleep waits for the given duration, returning early with the context error if the
+// context is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retry calls fn until it succeeds or fails with an error that isn't retried, waiting
+// between attempts as configured by WithRetry and WithWaitForModel.
+func (c *Client) retry(ctx context.Context, fn func() error) error {
+	var retries, modelLoadingWaits int
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		var delay time.Duration
+		if d, ok := modelLoadingDelay(err); ok && c.waitForModel && modelLoadingWaits < maxModelLoadingWaits {
+			modelLoadingWaits++
+			delay = d
+		} else if isRetryable(err) && retries < c.maxRetries {
+			delay = c.backoff(err, retries)
+			retries++
+		} else {
+			return err
+		}
+
+		if c.retryCallback != nil {
+			c.retryCallback(retries+modelLoadingWaits, err, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// isRetryable reports whether err is a rate limit or server error.
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the delay before the given retry of a request that failed with err. It
+// honors the Retry-After header and otherwise uses exponential backoff with jitter.
+func (c *Client) backoff(err error, retry int) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		return apiErr.retryAfter
+	}
+
+	delay := c.retryBaseDelay << retry
+	if delay <= 0 {
+		return 0
+	}
+	// Wait between half and all of the delay, so that clients don't retry in lockstep.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number of seconds or
+// an HTTP date. It returns 0 when the value is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
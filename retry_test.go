@@ -0,0 +1,124 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	// failing returns a handler that fails with the given status code the first n times.
+	failing := func(n int, status int, attempts *int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			*attempts++
+			if *attempts <= n {
+				w.Header().Set("Retry-After", "0.01")
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": "123"}`))
+		}
+	}
+
+	t.Run("RateLimited", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, failing(2, http.StatusTooManyRequests, &attempts), WithRetry(3, time.Millisecond))
+
+		completion, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "123", completion.ID)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, failing(5, http.StatusServiceUnavailable, &attempts), WithRetry(2, time.Millisecond))
+
+		_, err := c.ChatCompletion(messages)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, failing(1, http.StatusBadRequest, &attempts), WithRetry(3, time.Millisecond))
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		var (
+			attempts int
+			retries  []int
+			delays   []time.Duration
+		)
+		callback := func(attempt int, err error, delay time.Duration) {
+			var apiErr *APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+			retries = append(retries, attempt)
+			delays = append(delays, delay)
+		}
+		c := newTestClient(t, failing(2, http.StatusTooManyRequests, &attempts), WithRetry(3, time.Millisecond), WithRetryCallback(callback))
+
+		_, err := c.ChatCompletion(messages)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2}, retries)
+		assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond}, delays)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, WithRetry(3, time.Millisecond))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := c.ChatCompletionWithContext(ctx, messages)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	c := NewClient(WithRetry(5, 100*time.Millisecond))
+
+	for retry := 0; retry < 5; retry++ {
+		max := 100 * time.Millisecond << retry
+		delay := c.backoff(&APIError{StatusCode: http.StatusServiceUnavailable}, retry)
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+
+	assert.Equal(t, 2*time.Second, c.backoff(&APIError{StatusCode: http.StatusTooManyRequests, retryAfter: 2 * time.Second}, 0))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	assert.Equal(t, 1500*time.Millisecond, parseRetryAfter("1.5"))
+
+	delay := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.Greater(t, delay, 58*time.Second)
+}
@@ -0,0 +1,53 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// inflight tracks the requests in flight so that a shutdown can wait for them.
+// It is shared by a client and its profiles.
+type inflight struct {
+	mu           sync.Mutex
+	shuttingDown bool
+	wg           sync.WaitGroup
+}
+
+// begin registers a new request. It reports false when the client is shutting down.
+func (f *inflight) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.shuttingDown {
+		return false
+	}
+	f.wg.Add(1)
+	return true
+}
+
+// end marks a request registered by begin as finished.
+func (f *inflight) end() {
+	f.wg.Done()
+}
+
+// BeginShutdown makes the client reject new requests with ErrShuttingDown and waits for the
+// requests in flight to finish. It returns the context error if the context is done first.
+// Profiles created with WithProfile share the shutdown state of their client.
+func (c *Client) BeginShutdown(ctx context.Context) error {
+	c.inflight.mu.Lock()
+	c.inflight.shuttingDown = true
+	c.inflight.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
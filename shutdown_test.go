@@ -0,0 +1,64 @@
+package groq
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123"}`))
+	})
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	inflightErr := make(chan error, 1)
+	go func() {
+		_, err := c.ChatCompletion(messages)
+		inflightErr <- err
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- c.BeginShutdown(context.Background())
+	}()
+
+	// New requests are rejected while the in-flight one is still running.
+	assert.Eventually(t, func() bool {
+		_, err := c.ChatCompletion(messages)
+		return err == ErrShuttingDown
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	assert.Nil(t, <-inflightErr)
+	assert.Nil(t, <-shutdownErr)
+}
+
+func TestBeginShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	go func() {
+		_, _ = c.ChatCompletion([]Message{{Role: "user", Content: "Hello, world!"}})
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, c.BeginShutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,598 @@
+package groq
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChatCompletionStreamResponse represents a single chunk of a streamed chat completion.
+type ChatCompletionStreamResponse struct {
+	// ID represents the unique identifier for the chat completion.
+	ID string `json:"id,omitempty"`
+	// Object specifies the type of object returned, i.e. "chat.completion.chunk".
+	Object string `json:"object,omitempty"`
+	// Created indicates the timestamp when the completion was created.
+	Created int `json:"created,omitempty"`
+	// Model specifies the model used for the chat completion.
+	Model string `json:"model,omitempty"`
+	// Choices represents a slice of choice structures containing the incremental content of each choice.
+	Choices []struct {
+		// Index specifies the index of the choice.
+		Index int `json:"index"`
+		// Delta contains the content added to the message of the choice by this chunk.
+		Delta Message `json:"delta,omitempty"`
+		// Logprobs contains the log probabilities of the tokens of this chunk, when requested.
+		Logprobs *Logprobs `json:"logprobs,omitempty"`
+		// FinishReason indicates the reason why the choice was finished. It is only set on the last chunk of a choice.
+		FinishReason FinishReason `json:"finish_reason,omitempty"`
+	} `json:"choices,omitempty"`
+	// SystemFingerprint represents a unique identifier for the system.
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+	// Usage contains the usage statistics of the whole completion. It is only set on the
+	// last chunk when stream_options.include_usage is requested.
+	Usage *Usage `json:"usage,omitempty"`
+	// XGroq contains additional information about the Groq system.
+	XGroq struct {
+		// ID specifies the unique identifier for the Groq system.
+		ID string `json:"id,omitempty"`
+		// Usage contains the usage statistics of the whole completion, sent by Groq on the last chunk.
+		Usage *Usage `json:"usage,omitempty"`
+	} `json:"x_groq,omitempty"`
+}
+
+// ChatCompletionStream is a streamed chat completion. Chunks are read with Recv, and the
+// stream must be closed with Close once it is no longer needed. Close may be called from
+// another goroutine to interrupt a blocked Recv, which then returns ErrStreamClosed.
+type ChatCompletionStream struct {
+	reader    *bufio.Reader
+	done      bool
+	closeOnce sync.Once
+	onClose   func()
+
+	// mu guards the fields that Close and Usage read while Recv may be running.
+	mu       sync.Mutex
+	response *http.Response
+	usage    *Usage
+	closed   bool
+	// failed is set once the stream fails, so that it isn't recorded as a completion.
+	failed bool
+	// firstChunkAt and doneAt are the times the first chunk and the end of the stream were
+	// received, for TokensPerSecond.
+	firstChunkAt time.Time
+	doneAt       time.Time
+
+	// reconnect reopens the stream after a premature close, at most reconnects times.
+	// It is nil unless the client is created with WithStreamReconnect.
+	reconnect  func(lastEventID, content string) (*http.Response, error)
+	reconnects int
+	// lastEventID is the ID of the last event received, if the server sends IDs.
+	lastEventID string
+	// resumedWithID is set when the connection was last reopened with lastEventID, and
+	// ignoreEventIDs once the server ignored it and started the stream over.
+	resumedWithID  bool
+	ignoreEventIDs bool
+	// content is the content of the first choice received so far.
+	content strings.Builder
+
+	// onDone is called once the stream ends or is closed, whichever comes first, with the
+	// model that served it and the usage, unless the stream failed.
+	onDone   func(modelServed string, usage *Usage)
+	doneOnce sync.Once
+	// model is the model that served the stream, as reported in the chunks. It is guarded by mu.
+	model string
+
+	// body is the request the stream was opened with.
+	body RequestBody
+}
+
+// ChatCompletionStream sends a streaming request to the Groq API for chat completions and
+// returns the stream of chunks. The request fails like ChatCompletion when the API responds
+// with an unexpected status code.
+func (c *Client) ChatCompletionStream(ctx context.Context, messages []Message, options ...Option) (*ChatCompletionStream, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	body, err := c.buildBody(messages, options, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dryRun {
+		return nil, newDryRunError(body)
+	}
+
+	if !c.inflight.begin() {
+		return nil, ErrShuttingDown
+	}
+
+	// Closing the stream cancels its context, which also aborts a reconnect in progress.
+	var cancel context.CancelFunc
+	if body.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, body.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	onClose := func() {
+		cancel()
+		c.inflight.end()
+	}
+
+	var requestID string
+	if c.requestIDGenerator != nil {
+		requestID = c.requestIDGenerator()
+	}
+
+	var resp *http.Response
+	err = c.retry(ctx, func() error {
+		var err error
+		resp, err = c.post(ctx, body, requestID)
+		return err
+	})
+	if err != nil {
+		onClose()
+		if requestID != "" {
+			return nil, &RequestError{RequestID: requestID, Err: err}
+		}
+		return nil, err
+	}
+
+	stream := &ChatCompletionStream{
+		response: resp,
+		reader:   bufio.NewReader(resp.Body),
+		onClose:  onClose,
+		body:     body,
+	}
+
+	if c.completionHook != nil {
+		stream.onDone = func(modelServed string, usage *Usage) {
+			record := CompletionRecord{
+				Model:       body.Model,
+				ModelServed: modelServed,
+				RequestID:   requestID,
+				Stream:      true,
+				Metadata:    MetadataFromContext(ctx),
+			}
+			if usage != nil {
+				record.Usage = *usage
+			}
+			c.completionHook(ctx, record)
+		}
+	}
+
+	if c.streamReconnects > 0 {
+		stream.reconnects = c.streamReconnects
+		stream.reconnect = func(lastEventID, content string) (*http.Response, error) {
+			resumed := body
+			if lastEventID != "" {
+				resumed.lastEventID = lastEventID
+			} else if content != "" {
+				resumed.Messages = append(append([]Message(nil), body.Messages...), Message{Role: "assistant", Content: content})
+			}
+
+			var resp *http.Response
+			err := c.retry(ctx, func() error {
+				var err error
+				resp, err = c.post(ctx, resumed, requestID)
+				return err
+			})
+			return resp, err
+		}
+	}
+
+	return stream, nil
+}
+
+// Recv returns the next chunk of the stream. It returns io.EOF once the API signals the end
+// of the stream, and io.ErrUnexpectedEOF if the connection is closed before that and can't
+// be reopened as configured by WithStreamReconnect. Once the stream is closed, it returns
+// ErrStreamClosed.
+func (s *ChatCompletionStream) Recv() (*ChatCompletionStreamResponse, error) {
+	chunk, err := s.recv()
+	if err != nil && err != io.EOF && err != ErrStreamClosed {
+		s.fail()
+	}
+	return chunk, err
+}
+
+// recv reads and decodes the next chunk of the stream, reconnecting if needed.
+func (s *ChatCompletionStream) recv() (*ChatCompletionStreamResponse, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	if s.isClosed() {
+		return nil, ErrStreamClosed
+	}
+
+	data, err := s.readEvent()
+	for err != nil && s.canReconnect(err) {
+		if err = s.reopen(); err == nil {
+			data, err = s.readEvent()
+			if err == nil && s.restarted(data) {
+				// The server ignored Last-Event-ID, so the stream is resumed from the
+				// content received so far instead.
+				s.ignoreEventIDs = true
+				err = io.ErrUnexpectedEOF
+			}
+		}
+	}
+	if err != nil {
+		if s.isClosed() {
+			return nil, ErrStreamClosed
+		}
+		return nil, err
+	}
+
+	if data == "[DONE]" {
+		s.done = true
+		s.mu.Lock()
+		s.doneAt = time.Now()
+		s.mu.Unlock()
+		s.finish()
+		return nil, io.EOF
+	}
+
+	// Errors that occur after the response has started are sent as an event.
+	var event struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &event); err == nil && event.Error != nil {
+		return nil, event.Error
+	}
+
+	chunk := ChatCompletionStreamResponse{}
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.firstChunkAt.IsZero() {
+		s.firstChunkAt = time.Now()
+	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	} else if chunk.XGroq.Usage != nil {
+		s.usage = chunk.XGroq.Usage
+	}
+	if chunk.Model != "" {
+		s.model = chunk.Model
+	}
+	s.mu.Unlock()
+
+	if s.reconnect != nil {
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				s.content.WriteString(choice.Delta.Content)
+			}
+		}
+	}
+
+	return &chunk, nil
+}
+
+// reopen replaces the connection of a stream that closed prematurely with a new one that
+// continues where the stream left off.
+func (s *ChatCompletionStream) reopen() error {
+	s.reconnects--
+	s.response.Body.Close()
+
+	lastEventID := s.lastEventID
+	if s.ignoreEventIDs {
+		lastEventID = ""
+	}
+	s.resumedWithID = lastEventID != ""
+
+	resp, err := s.reconnect(lastEventID, s.content.String())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		resp.Body.Close()
+		return ErrStreamClosed
+	}
+	s.response = resp
+	s.reader = bufio.NewReader(resp.Body)
+	return nil
+}
+
+// isClosed reports whether Close has been called.
+func (s *ChatCompletionStream) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// canReconnect reports whether the stream is reopened after err. Only a connection that
+// dropped is, not a closed stream nor one whose context is done.
+func (s *ChatCompletionStream) canReconnect(err error) bool {
+	if s.reconnect == nil || s.reconnects <= 0 || s.isClosed() {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &netErr)
+}
+
+// restarted reports whether data, the first event after the stream was resumed with
+// Last-Event-ID, starts the completion over instead, as the role of the first chunk shows.
+func (s *ChatCompletionStream) restarted(data string) bool {
+	if !s.resumedWithID {
+		return false
+	}
+	var chunk ChatCompletionStreamResponse
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return false
+	}
+	for _, choice := range chunk.Choices {
+		if choice.Delta.Role != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage returns the usage statistics of the completion once they have been received, which
+// is normally with the last chunk, before Recv returns io.EOF. It returns nil until then.
+func (s *ChatCompletionStream) Usage() *Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage
+}
+
+// TokensPerSecond returns the throughput of the stream as measured by the client, i.e. the
+// number of completion tokens divided by the time from the first chunk to the end of the
+// stream. Unlike ChatCompletionResponse.TokensPerSecond, it includes the network time. It
+// returns 0 until the stream has ended, and when the API doesn't report the usage.
+func (s *ChatCompletionStream) TokensPerSecond() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.usage == nil || s.doneAt.IsZero() {
+		return 0
+	}
+	elapsed := s.doneAt.Sub(s.firstChunkAt).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.usage.CompletionTokens) / elapsed
+}
+
+// readEvent reads the next server-sent event and returns its data. Lines are read whole,
+// however they are split across reads of the connection. As the SSE spec requires, an event
+// that isn't ended by a blank line when the connection closes is discarded, and
+// io.ErrUnexpectedEOF is returned.
+func (s *ChatCompletionStream) readEvent() (string, error) {
+	var (
+		data []string
+		id   *string
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return "", err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			// A blank line ends the event. The ID only counts once the event is complete,
+			// so that a resumed stream doesn't skip a truncated event.
+			if id != nil {
+				s.lastEventID = *id
+			}
+			if len(data) > 0 {
+				return strings.Join(data, "\n"), nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "id:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "id:"), " ")
+			id = &value
+		}
+		// Comments and other fields, such as event, are ignored.
+	}
+}
+
+// Close closes the stream and the underlying connection, interrupting a Recv in progress.
+// It is safe to call more than once and from another goroutine than Recv.
+func (s *ChatCompletionStream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		// A stream closed before its end, e.g. with ErrStopStream, is still recorded.
+		s.finish()
+
+		s.mu.Lock()
+		s.closed = true
+		response := s.response
+		s.mu.Unlock()
+
+		err = response.Body.Close()
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return err
+}
+
+// finish calls onDone, if set, unless it has been called already or the stream failed.
+func (s *ChatCompletionStream) finish() {
+	if s.onDone == nil {
+		return
+	}
+	s.doneOnce.Do(func() {
+		s.mu.Lock()
+		model, usage, failed := s.model, s.usage, s.failed
+		s.mu.Unlock()
+		if !failed {
+			s.onDone(model, usage)
+		}
+	})
+}
+
+// fail marks the stream as failed.
+func (s *ChatCompletionStream) fail() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = true
+}
+
+// ChatCompletionStreamCallback streams a chat completion, calling onDelta with every chunk,
+// and returns the response assembled from all chunks. If onDelta returns ErrStopStream, the
+// stream is closed and the response assembled so far is returned without an error; any other
+// error from onDelta aborts the stream and is returned.
+//
+// The output filter, WithStripCodeFences and WithErrorOnFinishReasons apply to the assembled
+// response only; the chunks passed to onDelta are unfiltered.
+func (c *Client) ChatCompletionStreamCallback(ctx context.Context, messages []Message, onDelta func(*ChatCompletionStreamResponse) error, options ...Option) (*ChatCompletionResponse, error) {
+	stream, err := c.ChatCompletionStream(ctx, messages, options...)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	completion := &ChatCompletionResponse{}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return c.finishStream(stream, completion)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		completion.appendChunk(chunk)
+
+		if err := onDelta(chunk); err != nil {
+			if errors.Is(err, ErrStopStream) {
+				return c.finishStream(stream, completion)
+			}
+			stream.fail()
+			return nil, err
+		}
+	}
+}
+
+// finishStream runs the output filter and the per-request post-processing on a response
+// assembled from the chunks of stream, then passes it to the onFinish function, if any.
+func (c *Client) finishStream(stream *ChatCompletionStream, completion *ChatCompletionResponse) (*ChatCompletionResponse, error) {
+	if err := c.filterOutput(completion); err != nil {
+		return nil, err
+	}
+	completion, err := finishCompletion(stream.body, completion)
+	if err != nil {
+		return nil, err
+	}
+
+	if stream.body.onFinish != nil {
+		stream.body.onFinish(completion)
+	}
+	return completion, nil
+}
+
+// ChatCompletionStreamTo streams a chat completion, writing the content of the first choice
+// to w as it arrives, and returns the whole content and the usage statistics, which are
+// zero unless the API reports them. If w has a Flush method, such as a *bufio.Writer or an
+// http.ResponseWriter, it is flushed after every write so the output appears in real time.
+// A write error aborts the stream and is returned. Like ChatCompletionStreamCallback, it
+// filters the returned content but not what is written to w.
+func (c *Client) ChatCompletionStreamTo(ctx context.Context, w io.Writer, messages []Message, options ...Option) (string, Usage, error) {
+	completion, err := c.ChatCompletionStreamCallback(ctx, messages, func(chunk *ChatCompletionStreamResponse) error {
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 || choice.Delta.Content == "" {
+				continue
+			}
+			if _, err := io.WriteString(w, choice.Delta.Content); err != nil {
+				return err
+			}
+			if err := flush(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, options...)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	content, _ := completion.FirstContent()
+	return content, completion.Usage, nil
+}
+
+// flush flushes w if it supports it.
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case http.Flusher:
+		f.Flush()
+	}
+	return nil
+}
+
+// appendChunk adds a chunk of a streamed completion to the response.
+func (r *ChatCompletionResponse) appendChunk(chunk *ChatCompletionStreamResponse) {
+	if r.ID == "" {
+		r.ID = chunk.ID
+		r.Object = "chat.completion"
+		r.Created = chunk.Created
+		r.Model = chunk.Model
+		r.SystemFingerprint = chunk.SystemFingerprint
+		r.XGroq.ID = chunk.XGroq.ID
+	}
+
+	for _, delta := range chunk.Choices {
+		if delta.Index < 0 {
+			continue
+		}
+		for len(r.Choices) <= delta.Index {
+			r.Choices = append(r.Choices, Choice{Index: len(r.Choices)})
+		}
+
+		choice := &r.Choices[delta.Index]
+		if delta.Delta.Role != "" {
+			choice.Message.Role = delta.Delta.Role
+		}
+		choice.Message.Content += delta.Delta.Content
+		for _, call := range delta.Delta.ToolCalls {
+			// A call with an ID starts a new call; the others continue the last one.
+			if call.ID != "" || len(choice.Message.ToolCalls) == 0 {
+				choice.Message.ToolCalls = append(choice.Message.ToolCalls, call)
+				continue
+			}
+			last := &choice.Message.ToolCalls[len(choice.Message.ToolCalls)-1]
+			last.Function.Name += call.Function.Name
+			last.Function.Arguments += call.Function.Arguments
+		}
+		if delta.Logprobs != nil {
+			if choice.Logprobs == nil {
+				choice.Logprobs = &Logprobs{}
+			}
+			choice.Logprobs.Content = append(choice.Logprobs.Content, delta.Logprobs.Content...)
+		}
+		if delta.FinishReason != "" {
+			choice.FinishReason = delta.FinishReason
+		}
+	}
+
+	if chunk.Usage != nil {
+		r.Usage = *chunk.Usage
+	} else if chunk.XGroq.Usage != nil {
+		r.Usage = *chunk.XGroq.Usage
+	}
+}
@@ -0,0 +1,515 @@
+package groq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// streamHandler returns a handler that writes the given server-sent events, flushing after
+// each write so that they arrive in separate reads on the client.
+func streamHandler(writes ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, write := range writes {
+			_, _ = io.WriteString(w, write)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// collect reads the whole stream and returns the concatenated content and the final error.
+func collect(stream *ChatCompletionStream) (string, error) {
+	var content strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return content.String(), err
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+	}
+}
+
+func TestChatCompletionStream(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Success", func(t *testing.T) {
+		var stream bool
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			var body RequestBody
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			stream = body.Stream
+
+			streamHandler(
+				"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"role\": \"assistant\", \"content\": \"Hel\"}}]}\n\n",
+				// A chunk split across writes.
+				"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, ",
+				"\"delta\": {\"content\": \"lo\"}}]}\n\n",
+				": keep-alive\n\n",
+				"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {}, \"finish_reason\": \"stop\"}]}\r\n\r\n",
+				"data: [DONE]\n\n",
+			)(w, r)
+		})
+
+		s, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer s.Close()
+
+		content, err := collect(s)
+
+		assert.True(t, stream)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, "Hello", content)
+
+		// The stream stays at EOF.
+		_, err = s.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("UnexpectedEOF", func(t *testing.T) {
+		c := newTestClient(t, streamHandler(
+			"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n",
+		))
+
+		s, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer s.Close()
+
+		content, err := collect(s)
+
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+		assert.Equal(t, "Hel", content)
+	})
+
+	t.Run("TruncatedEvent", func(t *testing.T) {
+		for name, truncated := range map[string]string{
+			"MidLine":     "data: {\"choices\":[{\"index\":0,\"del",
+			"NoBlankLine": "data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo\"}}]}\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				c := newTestClient(t, streamHandler(
+					"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n",
+					truncated,
+				))
+
+				s, err := c.ChatCompletionStream(context.Background(), messages)
+				assert.Nil(t, err)
+				defer s.Close()
+
+				content, err := collect(s)
+
+				// The incomplete event is discarded.
+				assert.Equal(t, io.ErrUnexpectedEOF, err)
+				assert.Equal(t, "Hel", content)
+			})
+		}
+	})
+
+	t.Run("ErrorEvent", func(t *testing.T) {
+		c := newTestClient(t, streamHandler(
+			"data: {\"error\": {\"message\": \"Internal error\", \"type\": \"internal_server_error\"}}\n\n",
+		))
+
+		s, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer s.Close()
+
+		_, err = s.Recv()
+
+		apiErr, ok := err.(*APIError)
+		assert.True(t, ok)
+		assert.Equal(t, "Internal error", apiErr.Message)
+		assert.Equal(t, 0, apiErr.StatusCode)
+		assert.EqualError(t, err, "stream error: Internal error")
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		c := newTestClient(t, respondWith(http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached"}}`))
+
+		_, err := c.ChatCompletionStream(context.Background(), messages)
+
+		apiErr, ok := err.(*APIError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	})
+}
+
+func TestChatCompletionStreamCloseDuringRecv(t *testing.T) {
+	// The connection stays open after the first chunk until the stream is closed.
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		streamHandler("data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n")(w, r)
+		<-r.Context().Done()
+	}, WithStreamReconnect(3), WithCompletionHook(func(ctx context.Context, record CompletionRecord) {}))
+
+	stream, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+
+	errs := make(chan error)
+	go func() {
+		_, err := stream.Recv()
+		errs <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, stream.Close())
+	assert.Equal(t, ErrStreamClosed, <-errs)
+	assert.Nil(t, stream.Usage())
+}
+
+func TestChatCompletionStreamUsage(t *testing.T) {
+	var includeUsage bool
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body RequestBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		includeUsage = body.StreamOptions != nil && body.StreamOptions.IncludeUsage
+
+		streamHandler(
+			"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}, \"finish_reason\": \"stop\"}]}\n\n",
+			"data: {\"id\": \"1\", \"choices\": [], \"usage\": {\"prompt_tokens\": 5, \"completion_tokens\": 1, \"total_tokens\": 6}}\n\n",
+			"data: [DONE]\n\n",
+		)(w, r)
+	})
+
+	s, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}}, WithStreamUsage(true))
+	assert.Nil(t, err)
+	defer s.Close()
+
+	assert.Nil(t, s.Usage())
+	_, err = collect(s)
+
+	assert.True(t, includeUsage)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 6, s.Usage().TotalTokens)
+}
+
+func TestChatCompletionStreamTokensPerSecond(t *testing.T) {
+	// The tokens take 100ms to arrive after the first chunk.
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, "data: {\"id\": \"1\", \"choices\": [], \"x_groq\": {\"usage\": {\"completion_tokens\": 10}}}\n\ndata: [DONE]\n\n")
+	})
+
+	stream, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	assert.Nil(t, err)
+	// Nothing is measured until the stream ends.
+	assert.Equal(t, 0.0, stream.TokensPerSecond())
+
+	_, err = collect(stream)
+	assert.Equal(t, io.EOF, err)
+	assert.InDelta(t, 100, stream.TokensPerSecond(), 30)
+}
+
+func TestChatCompletionStreamCallback(t *testing.T) {
+	handler := streamHandler(
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {\"role\": \"assistant\", \"content\": \"Hel\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"model\": \"llama3-8b-8192\", \"choices\": [{\"index\": 0, \"delta\": {}, \"finish_reason\": \"stop\"}], \"x_groq\": {\"id\": \"req_1\", \"usage\": {\"total_tokens\": 7}}}\n\n",
+		"data: [DONE]\n\n",
+	)
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("Complete", func(t *testing.T) {
+		c := newTestClient(t, handler)
+
+		chunks := 0
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			chunks++
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, chunks)
+		assert.Equal(t, "1", completion.ID)
+		assert.Equal(t, "llama3-8b-8192", completion.Model)
+		assert.Equal(t, Message{Role: "assistant", Content: "Hello"}, completion.Choices[0].Message)
+		assert.Equal(t, FinishReasonStop, completion.Choices[0].FinishReason)
+		assert.Equal(t, 7, completion.Usage.TotalTokens)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		c := newTestClient(t, handler)
+
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return ErrStopStream
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Hel", completion.Choices[0].Message.Content)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		c := newTestClient(t, handler)
+		callbackErr := errors.New("callback failed")
+
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return callbackErr
+		})
+
+		assert.Nil(t, completion)
+		assert.ErrorIs(t, err, callbackErr)
+	})
+
+	t.Run("OutputFilter", func(t *testing.T) {
+		c := newTestClient(t, handler, WithOutputFilter(func(content string) (string, bool) {
+			return strings.ToUpper(content), content != "Hel"
+		}))
+		noop := func(chunk *ChatCompletionStreamResponse) error { return nil }
+
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, noop)
+		assert.Nil(t, err)
+		assert.Equal(t, "HELLO", completion.Choices[0].Message.Content)
+
+		completion, err = c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return ErrStopStream
+		})
+		assert.Nil(t, completion)
+		assert.ErrorIs(t, err, ErrContentBlocked)
+	})
+
+	t.Run("OnFinish", func(t *testing.T) {
+		c := newTestClient(t, handler)
+
+		var finished []*ChatCompletionResponse
+		onFinish := WithOnFinish(func(completion *ChatCompletionResponse) {
+			finished = append(finished, completion)
+		})
+
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return nil
+		}, onFinish)
+		assert.Nil(t, err)
+		assert.Equal(t, []*ChatCompletionResponse{completion}, finished)
+		assert.Equal(t, 7, finished[0].Usage.TotalTokens)
+
+		// It isn't called when the stream fails.
+		finished = nil
+		_, err = c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return errors.New("callback failed")
+		}, onFinish)
+		assert.NotNil(t, err)
+		assert.Nil(t, finished)
+	})
+
+	t.Run("ErrorOnFinishReasons", func(t *testing.T) {
+		c := newTestClient(t, handler)
+
+		completion, err := c.ChatCompletionStreamCallback(context.Background(), messages, func(chunk *ChatCompletionStreamResponse) error {
+			return nil
+		}, WithErrorOnFinishReasons(FinishReasonStop))
+
+		assert.Nil(t, completion)
+		var finishErr *FinishReasonError
+		assert.ErrorAs(t, err, &finishErr)
+		assert.Equal(t, "Hello", finishErr.Response.Choices[0].Message.Content)
+	})
+}
+
+// failingWriter fails every write after the first.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("broken pipe")
+	}
+	return len(p), nil
+}
+
+func TestChatCompletionStreamTo(t *testing.T) {
+	c := newTestClient(t, streamHandler(
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"role\": \"assistant\", \"content\": \"Hel\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo!\"}, \"finish_reason\": \"stop\"}], \"x_groq\": {\"usage\": {\"total_tokens\": 12}}}\n\n",
+		"data: [DONE]\n\n",
+	))
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	var out bytes.Buffer
+	w := bufio.NewWriterSize(&out, 1024)
+	content, usage, err := c.ChatCompletionStreamTo(context.Background(), w, messages)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello!", content)
+	assert.Equal(t, "Hello!", out.String())
+	assert.Equal(t, 12, usage.TotalTokens)
+
+	_, _, err = c.ChatCompletionStreamTo(context.Background(), &failingWriter{}, messages)
+	assert.EqualError(t, err, "broken pipe")
+}
+
+func TestStreamReconnect(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+
+	t.Run("LastEventID", func(t *testing.T) {
+		var lastEventIDs []string
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			if len(lastEventIDs) == 1 {
+				// The connection drops after the first event.
+				streamHandler("id: 1\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n")(w, r)
+				return
+			}
+			streamHandler(
+				"id: 2\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo!\"}}]}\n\n",
+				"data: [DONE]\n\n",
+			)(w, r)
+		}, WithStreamReconnect(2))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer stream.Close()
+
+		content, err := collect(stream)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, "Hello!", content)
+		assert.Equal(t, []string{"", "1"}, lastEventIDs)
+	})
+
+	t.Run("MidEvent", func(t *testing.T) {
+		var lastEventIDs []string
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			if len(lastEventIDs) == 1 {
+				// The connection drops in the middle of the second event.
+				streamHandler(
+					"id: 1\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n",
+					"id: 2\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"del",
+				)(w, r)
+				return
+			}
+			streamHandler(
+				"id: 2\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo!\"}}]}\n\n",
+				"data: [DONE]\n\n",
+			)(w, r)
+		}, WithStreamReconnect(1))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer stream.Close()
+
+		content, err := collect(stream)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, "Hello!", content)
+		assert.Equal(t, []string{"", "1"}, lastEventIDs)
+	})
+
+	t.Run("Restarted", func(t *testing.T) {
+		var (
+			lastEventIDs []string
+			bodies       []RequestBody
+		)
+		first := streamHandler(
+			"id: 1\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"role\": \"assistant\", \"content\": \"Hel\"}}]}\n\n",
+		)
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			var body RequestBody
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			switch len(bodies) {
+			case 1, 2:
+				// The server ignores Last-Event-ID and starts over.
+				first(w, r)
+			default:
+				streamHandler(
+					"id: 2\ndata: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo!\"}}]}\n\n",
+					"data: [DONE]\n\n",
+				)(w, r)
+			}
+		}, WithStreamReconnect(2))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer stream.Close()
+
+		content, err := collect(stream)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, "Hello!", content)
+		assert.Equal(t, []string{"", "1", ""}, lastEventIDs)
+		assert.Equal(t, append(messages, Message{Role: "assistant", Content: "Hel"}), bodies[2].Messages)
+	})
+
+	t.Run("Prefill", func(t *testing.T) {
+		var bodies []RequestBody
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			var body RequestBody
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			if len(bodies) == 1 {
+				streamHandler("data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n")(w, r)
+				return
+			}
+			streamHandler(
+				"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo!\"}}]}\n\n",
+				"data: [DONE]\n\n",
+			)(w, r)
+		}, WithStreamReconnect(1))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer stream.Close()
+
+		content, err := collect(stream)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, "Hello!", content)
+		assert.Equal(t, 2, len(bodies))
+		assert.Equal(t, append(messages, Message{Role: "assistant", Content: "Hel"}), bodies[1].Messages)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		requests := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			streamHandler("data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n")(w, r)
+		}, WithStreamReconnect(3))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		assert.Nil(t, stream.Close())
+
+		// A closed stream is not mistaken for a dropped connection.
+		_, err = stream.Recv()
+		assert.Equal(t, ErrStreamClosed, err)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		attempts := 0
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			streamHandler("data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hi\"}}]}\n\n")(w, r)
+		}, WithStreamReconnect(2))
+
+		stream, err := c.ChatCompletionStream(context.Background(), messages)
+		assert.Nil(t, err)
+		defer stream.Close()
+
+		_, err = collect(stream)
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+		assert.Equal(t, 3, attempts)
+	})
+}
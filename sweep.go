@@ -0,0 +1,102 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// sweepConcurrency is the maximum number of requests a sweep runs at the same time.
+const sweepConcurrency = 4
+
+// TemperatureSweep sends the same messages once per temperature and returns the responses
+// keyed by temperature. Requests run concurrently, at most a few at a time. The temperature
+// overrides any temperature set in options. If any request fails, the first error is returned.
+func (c *Client) TemperatureSweep(ctx context.Context, messages []Message, temperatures []float64, options ...Option) (map[float64]*ChatCompletionResponse, error) {
+	variants := make([]Option, len(temperatures))
+	for i, temperature := range temperatures {
+		variants[i] = WithTemperature(temperature)
+	}
+
+	completions, err := c.sweep(ctx, messages, variants, options)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[float64]*ChatCompletionResponse, len(temperatures))
+	for i, temperature := range temperatures {
+		results[temperature] = completions[i]
+	}
+	return results, nil
+}
+
+// SeedSweep sends the same messages once per seed and returns the responses keyed by seed,
+// like TemperatureSweep. At temperature 0 it shows how deterministic the backend is, at
+// higher temperatures how diverse the outputs are.
+func (c *Client) SeedSweep(ctx context.Context, messages []Message, seeds []int, options ...Option) (map[int]*ChatCompletionResponse, error) {
+	variants := make([]Option, len(seeds))
+	for i, seed := range seeds {
+		variants[i] = WithSeed(seed)
+	}
+
+	completions, err := c.sweep(ctx, messages, variants, options)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int]*ChatCompletionResponse, len(seeds))
+	for i, seed := range seeds {
+		results[seed] = completions[i]
+	}
+	return results, nil
+}
+
+// sweep sends the same messages once per variant, an option applied after the others, and
+// returns the responses in the order of the variants. Requests run concurrently, at most
+// sweepConcurrency at a time. If any request fails, the first error is returned.
+func (c *Client) sweep(ctx context.Context, messages []Message, variants []Option, options []Option) ([]*ChatCompletionResponse, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		results  = make([]*ChatCompletionResponse, len(variants))
+		sem      = make(chan struct{}, sweepConcurrency)
+	)
+
+	for i, variant := range variants {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, variant Option) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			opts := append(append([]Option(nil), options...), variant)
+			completion, err := c.ChatCompletionWithContext(ctx, messages, opts...)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			results[i] = completion
+		}(i, variant)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
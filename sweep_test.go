@@ -0,0 +1,55 @@
+package groq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemperatureSweep(t *testing.T) {
+	// The mock server echoes the requested temperature as the content.
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Temperature float64 `json:"temperature"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"choices": [{"index": 0, "message": {"role": "assistant", "content": "%v"}}]}`, body.Temperature)
+	})
+
+	temperatures := []float64{0, 0.5, 1, 1.5, 2}
+	results, err := c.TemperatureSweep(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}}, temperatures, WithTemperature(0.7))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(temperatures), len(results))
+	for _, temperature := range temperatures {
+		assert.Equal(t, fmt.Sprint(temperature), results[temperature].Choices[0].Message.Content)
+	}
+}
+
+func TestSeedSweep(t *testing.T) {
+	// The mock server echoes the requested seed as the content.
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Seed *int `json:"seed"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"choices": [{"index": 0, "message": {"role": "assistant", "content": "%d"}}]}`, *body.Seed)
+	})
+
+	seeds := []int{0, 1, 42, 1234, 99999}
+	results, err := c.SeedSweep(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}}, seeds, WithTemperature(0), WithSeed(7))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(seeds), len(results))
+	for _, seed := range seeds {
+		assert.Equal(t, fmt.Sprint(seed), results[seed].Choices[0].Message.Content)
+	}
+}
@@ -0,0 +1,17 @@
+package groq
+
+// charsPerToken is the rough number of characters per token of English text.
+const charsPerToken = 4
+
+// tokensPerMessage is the rough number of tokens the chat template adds around each message.
+const tokensPerMessage = 4
+
+// estimatePromptTokens returns a rough estimate of the number of prompt tokens of messages.
+// It doesn't use the model's tokenizer, so it is only good for budgeting.
+func estimatePromptTokens(messages []Message) int {
+	tokens := 0
+	for _, message := range messages {
+		tokens += tokensPerMessage + (len(message.Role)+len(message.Name)+len(message.Content)+charsPerToken-1)/charsPerToken
+	}
+	return tokens
+}
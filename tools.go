@@ -0,0 +1,23 @@
+package groq
+
+import "encoding/json"
+
+// ToolResultMessage returns the "tool" message carrying the result of the tool call with
+// the given ID. The result is encoded as JSON, except for strings, which are used as is.
+// If the result can't be encoded, the content is a JSON object with the encoding error,
+// so that the model is told the call failed.
+func ToolResultMessage(toolCallID string, result interface{}) Message {
+	message := Message{Role: "tool", ToolCallID: toolCallID}
+
+	if content, ok := result.(string); ok {
+		message.Content = content
+		return message
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		content, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	message.Content = string(content)
+	return message
+}
@@ -0,0 +1,68 @@
+package groq
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTools(t *testing.T) {
+	var received map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": "123", "choices": [{"index": 0, "message": {"role": "assistant", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\": \"Paris\"}"}}]}, "finish_reason": "tool_calls"}]}`))
+	})
+
+	weather := Tool{
+		Type: "function",
+		Function: FunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the current weather in a city",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"city": map[string]string{"type": "string"}},
+				"required":   []string{"city"},
+			},
+		},
+	}
+
+	messages := []Message{{Role: "user", Content: "What's the weather in Paris?"}}
+	completion, err := c.ChatCompletion(messages, WithTools(weather), WithToolChoiceFunction("get_weather"))
+
+	assert.Nil(t, err)
+	tools := received["tools"].([]interface{})
+	assert.Equal(t, "get_weather", tools[0].(map[string]interface{})["function"].(map[string]interface{})["name"])
+	assert.Equal(t, map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "get_weather"}}, received["tool_choice"])
+
+	calls := completion.Choices[0].Message.ToolCalls
+	assert.Equal(t, 1, len(calls))
+	assert.Equal(t, "call_1", calls[0].ID)
+	assert.Equal(t, "get_weather", calls[0].Function.Name)
+	assert.JSONEq(t, `{"city": "Paris"}`, calls[0].Function.Arguments)
+
+	// The result is sent back as a tool message.
+	messages = append(messages, completion.Choices[0].Message, Message{Role: "tool", ToolCallID: "call_1", Content: `{"temperature": 21}`})
+	_, err = c.ChatCompletion(messages, WithTools(weather))
+
+	assert.Nil(t, err)
+	sent := received["messages"].([]interface{})
+	assert.Equal(t, "call_1", sent[2].(map[string]interface{})["tool_call_id"])
+	assert.Nil(t, received["tool_choice"])
+}
+
+func TestToolResultMessage(t *testing.T) {
+	message := ToolResultMessage("call_1", map[string]interface{}{"temperature": 21, "unit": "celsius"})
+	assert.Equal(t, "tool", message.Role)
+	assert.Equal(t, "call_1", message.ToolCallID)
+	assert.JSONEq(t, `{"temperature": 21, "unit": "celsius"}`, message.Content)
+
+	message = ToolResultMessage("call_2", "sunny")
+	assert.Equal(t, "sunny", message.Content)
+
+	message = ToolResultMessage("call_3", make(chan int))
+	assert.Contains(t, message.Content, `"error"`)
+}
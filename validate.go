@@ -0,0 +1,137 @@
+package groq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MessageError is returned when a message is rejected before the request is sent.
+type MessageError struct {
+	// Index is the position of the offending message.
+	Index int
+	// Reason describes what is wrong with the message.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *MessageError) Error() string {
+	return fmt.Sprintf("groq: invalid message at index %d: %s", e.Index, e.Reason)
+}
+
+// validRoles are the roles of messages accepted by the API.
+var validRoles = map[string]bool{
+	"system":    true,
+	"user":      true,
+	"assistant": true,
+	"tool":      true,
+}
+
+// validateMessages catches messages the Groq API rejects with an unhelpful 400.
+func validateMessages(messages []Message) error {
+	for i, message := range messages {
+		switch {
+		case message.Role == "" && message.Content == "":
+			return &MessageError{Index: i, Reason: "message has neither role nor content"}
+		case message.Role == "":
+			return &MessageError{Index: i, Reason: "message has no role"}
+		case !validRoles[message.Role]:
+			return &MessageError{Index: i, Reason: fmt.Sprintf("invalid role %q, must be system, user, assistant or tool", message.Role)}
+		case message.Role == "system" && message.Content == "":
+			return &MessageError{Index: i, Reason: "system message has empty content"}
+		}
+	}
+	return nil
+}
+
+// apiKeyPrefix is the prefix of Groq API keys.
+const apiKeyPrefix = "gsk_"
+
+// minAPIKeyLength is the length below which an API key is surely truncated. Current keys
+// are 56 characters long.
+const minAPIKeyLength = 40
+
+// validateAPIKey checks the format of an API key. The error never contains the key.
+func validateAPIKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: the key is empty, set GROQ_API_KEY or use WithAPIKey", ErrInvalidAPIKey)
+	case !strings.HasPrefix(key, apiKeyPrefix):
+		return fmt.Errorf("%w: the key doesn't start with %q", ErrInvalidAPIKey, apiKeyPrefix)
+	case len(key) < minAPIKeyLength:
+		return fmt.Errorf("%w: the key is too short (%d characters)", ErrInvalidAPIKey, len(key))
+	case strings.IndexFunc(key, unicode.IsSpace) >= 0:
+		return fmt.Errorf("%w: the key contains whitespace", ErrInvalidAPIKey)
+	}
+	return nil
+}
+
+// maxTopLogprobs is the maximum number of most likely tokens the API returns per position.
+const maxTopLogprobs = 20
+
+// validateTopLogprobs checks that top_logprobs, if set, is in the range the API accepts.
+func validateTopLogprobs(n *int) error {
+	if n != nil && (*n < 0 || *n > maxTopLogprobs) {
+		return fmt.Errorf("groq: top_logprobs must be between 0 and %d, got %d", maxTopLogprobs, *n)
+	}
+	return nil
+}
+
+// maxStopSequences is the maximum number of stop sequences the API accepts.
+const maxStopSequences = 4
+
+// Validate checks the body of a non-streaming request against the constraints documented
+// by the Groq API, so that invalid requests fail locally instead of with a 400. Setting
+// Stream is an error, since ChatCompletion can't decode a streamed response.
+// It is called by ChatCompletion when the client is created with WithStrictValidation.
+func (rb *RequestBody) Validate() error {
+	return rb.validate(false)
+}
+
+// validate checks the body of a request, which is a streaming request if streaming is set.
+func (rb *RequestBody) validate(streaming bool) error {
+	if len(rb.Messages) == 0 {
+		return ErrNoMessages
+	}
+	if err := validateMessages(rb.Messages); err != nil {
+		return err
+	}
+	if rb.Model == "" {
+		return ErrModelRequired
+	}
+	if rb.Temperature < 0 || rb.Temperature > 2 {
+		return fmt.Errorf("groq: temperature must be between 0 and 2, got %v", rb.Temperature)
+	}
+	if rb.TopP < 0 || rb.TopP > 1 {
+		return fmt.Errorf("groq: top_p must be between 0 and 1, got %v", rb.TopP)
+	}
+	if err := validateTopLogprobs(rb.TopLogprobs); err != nil {
+		return err
+	}
+	if rb.TopLogprobs != nil && !rb.Logprobs {
+		return errors.New("groq: top_logprobs requires logprobs")
+	}
+	if rb.N < 0 {
+		return fmt.Errorf("groq: n must not be negative, got %d", rb.N)
+	}
+	if p := rb.FrequencyPenalty; p != nil && (*p < -2 || *p > 2) {
+		return fmt.Errorf("groq: frequency_penalty must be between -2 and 2, got %v", *p)
+	}
+	if p := rb.PresencePenalty; p != nil && (*p < -2 || *p > 2) {
+		return fmt.Errorf("groq: presence_penalty must be between -2 and 2, got %v", *p)
+	}
+	if rb.MaxTokens < 0 {
+		return fmt.Errorf("groq: max_tokens must not be negative, got %d", rb.MaxTokens)
+	}
+	if len(rb.Stop) > maxStopSequences {
+		return fmt.Errorf("groq: at most %d stop sequences are allowed, got %d", maxStopSequences, len(rb.Stop))
+	}
+	if rb.Stream && !streaming {
+		return ErrStreamNotSupported
+	}
+	if rb.Stream && rb.ResponseFormat.Type == "json_object" {
+		return errors.New("groq: JSON mode is not supported with streaming")
+	}
+	return nil
+}
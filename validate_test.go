@@ -0,0 +1,190 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []Message
+		index    int
+	}{
+		{
+			name:     "Valid",
+			messages: []Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: "Hello"}},
+			index:    -1,
+		},
+		{
+			name:     "AllEmpty",
+			messages: []Message{{Role: "user", Content: "Hello"}, {}},
+			index:    1,
+		},
+		{
+			name:     "NoRole",
+			messages: []Message{{Role: "user", Content: "Hello"}, {Content: "Hi"}},
+			index:    1,
+		},
+		{
+			name:     "InvalidRole",
+			messages: []Message{{Role: "bot", Content: "Hello"}},
+			index:    0,
+		},
+		{
+			name:     "Tool",
+			messages: []Message{{Role: "user", Content: "Hello"}, {Role: "tool", ToolCallID: "call_1", Content: "{}"}},
+			index:    -1,
+		},
+		{
+			name:     "EmptySystem",
+			messages: []Message{{Role: "system", Content: ""}, {Role: "user", Content: "Hello"}},
+			index:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessages(tt.messages)
+			if tt.index < 0 {
+				assert.Nil(t, err)
+				return
+			}
+
+			var messageErr *MessageError
+			assert.True(t, errors.As(err, &messageErr))
+			assert.Equal(t, tt.index, messageErr.Index)
+		})
+	}
+}
+
+func TestChatCompletionRejectsEmptyMessage(t *testing.T) {
+	c := NewClient(WithAPIKey("test-key"))
+
+	_, err := c.ChatCompletion([]Message{{Content: "", Role: "system"}, {Content: "What is groq cloud?", Role: "user"}})
+
+	var messageErr *MessageError
+	assert.True(t, errors.As(err, &messageErr))
+	assert.Equal(t, 0, messageErr.Index)
+}
+
+func TestChatCompletionRejectsNoMessages(t *testing.T) {
+	c := NewClient(WithAPIKey("test-key"))
+
+	_, err := c.ChatCompletion(nil)
+
+	assert.ErrorIs(t, err, ErrNoMessages)
+}
+
+func TestRequestBodyValidate(t *testing.T) {
+	valid := func() RequestBody {
+		return RequestBody{
+			Messages:    []Message{{Role: "user", Content: "Hello"}},
+			Model:       "llama3-8b-8192",
+			Temperature: 1,
+			TopP:        1,
+			MaxTokens:   1024,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*RequestBody)
+		valid  bool
+	}{
+		{name: "Valid", modify: func(rb *RequestBody) {}, valid: true},
+		{name: "NoMessages", modify: func(rb *RequestBody) { rb.Messages = nil }},
+		{name: "EmptyMessage", modify: func(rb *RequestBody) { rb.Messages = append(rb.Messages, Message{}) }},
+		{name: "NoModel", modify: func(rb *RequestBody) { rb.Model = "" }},
+		{name: "Temperature", modify: func(rb *RequestBody) { rb.Temperature = 2.5 }},
+		{name: "TopP", modify: func(rb *RequestBody) { rb.TopP = -0.1 }},
+		{name: "N", modify: WithN(-1)},
+		{name: "Logprobs", modify: func(rb *RequestBody) { WithLogprobs(true)(rb); WithTopLogprobs(20)(rb) }, valid: true},
+		{name: "TopLogprobs", modify: func(rb *RequestBody) { WithLogprobs(true)(rb); WithTopLogprobs(21)(rb) }},
+		{name: "TopLogprobsWithoutLogprobs", modify: WithTopLogprobs(5)},
+		{name: "Penalties", modify: func(rb *RequestBody) { WithFrequencyPenalty(-2)(rb); WithPresencePenalty(2)(rb) }, valid: true},
+		{name: "FrequencyPenalty", modify: WithFrequencyPenalty(2.5)},
+		{name: "PresencePenalty", modify: WithPresencePenalty(-3)},
+		{name: "MaxTokens", modify: func(rb *RequestBody) { rb.MaxTokens = -1 }},
+		{name: "Stop", modify: func(rb *RequestBody) { rb.Stop = Stop{"a", "b", "c", "d", "e"} }},
+		{name: "Stream", modify: func(rb *RequestBody) { rb.Stream = true }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := valid()
+			tt.modify(&body)
+
+			err := body.Validate()
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestStrictValidation(t *testing.T) {
+	c := NewClient(WithAPIKey("test-key"), WithStrictValidation())
+
+	_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello"}}, WithTemperature(3))
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "temperature")
+}
+
+func TestStrictValidationStream(t *testing.T) {
+	c := newTestClient(t, streamHandler("data: [DONE]\n\n"), WithStrictValidation())
+	messages := []Message{{Role: "user", Content: "Hello"}}
+	stream := func(rb *RequestBody) { rb.Stream = true }
+
+	_, err := c.ChatCompletion(messages, stream)
+	assert.ErrorIs(t, err, ErrStreamNotSupported)
+
+	s, err := c.ChatCompletionStream(context.Background(), messages)
+	assert.Nil(t, err)
+	s.Close()
+
+	_, err = c.ChatCompletionStream(context.Background(), messages, WithJSON())
+	assert.NotNil(t, err)
+}
+
+func TestStrictKeyValidation(t *testing.T) {
+	valid := "gsk_" + strings.Repeat("a1B2", 13)
+
+	tests := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{name: "Valid", key: valid, valid: true},
+		{name: "Empty", key: ""},
+		{name: "WrongPrefix", key: "sk-" + strings.Repeat("a1B2", 13)},
+		{name: "TooShort", key: "gsk_abc"},
+		{name: "Whitespace", key: valid[:20] + " " + valid[20:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(WithStrictKeyValidation(), WithAPIKey(tt.key))
+			if tt.valid {
+				assert.Nil(t, c.err)
+				return
+			}
+
+			_, err := c.ChatCompletion([]Message{{Role: "user", Content: "Hello"}})
+			assert.ErrorIs(t, err, ErrInvalidAPIKey)
+			if tt.key != "" {
+				assert.NotContains(t, err.Error(), tt.key)
+			}
+		})
+	}
+
+	// Without the option, keys are not checked.
+	assert.Nil(t, NewClient(WithAPIKey("test-key")).err)
+}
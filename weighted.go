@@ -0,0 +1,45 @@
+package groq
+
+import "math/rand"
+
+// WeightedModel is a model with the relative share of requests it receives.
+type WeightedModel struct {
+	// Model is the ID or alias of the model.
+	Model string
+	// Weight is the share of requests of the model, relative to the sum of all weights.
+	// Models with a weight of 0 or less receive no requests.
+	Weight float64
+}
+
+// pickWeightedModel picks a model at random according to the weights. It reports false
+// when no model has a positive weight.
+func pickWeightedModel(models []WeightedModel) (string, bool) {
+	var total float64
+	for _, m := range models {
+		if m.Weight > 0 {
+			total += m.Weight
+		}
+	}
+	if total <= 0 {
+		return "", false
+	}
+
+	r := rand.Float64() * total
+	for _, m := range models {
+		if m.Weight <= 0 {
+			continue
+		}
+		if r < m.Weight {
+			return m.Model, true
+		}
+		r -= m.Weight
+	}
+
+	// Rounding may leave r just above the last weight.
+	for i := len(models) - 1; i >= 0; i-- {
+		if models[i].Weight > 0 {
+			return models[i].Model, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package groq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickWeightedModel(t *testing.T) {
+	models := []WeightedModel{
+		{Model: "llama3-8b-8192", Weight: 9},
+		{Model: "llama-3.1-8b-instant", Weight: 1},
+		{Model: "gemma-7b-it", Weight: 0},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		model, ok := pickWeightedModel(models)
+		assert.True(t, ok)
+		counts[model]++
+	}
+
+	assert.InDelta(t, 9000, counts["llama3-8b-8192"], 300)
+	assert.InDelta(t, 1000, counts["llama-3.1-8b-instant"], 300)
+	assert.Equal(t, 0, counts["gemma-7b-it"])
+
+	_, ok := pickWeightedModel([]WeightedModel{{Model: "gemma-7b-it"}})
+	assert.False(t, ok)
+}
+
+func TestWeightedModels(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Hello, world!"}}
+	c := NewClient(
+		WithWeightedModels([]WeightedModel{{Model: "new", Weight: 1}}),
+		WithModelAliases(map[string]string{"new": "llama-3.1-8b-instant"}),
+		WithDefaultModel("llama3-70b-8192"),
+	)
+
+	assert.Equal(t, "llama-3.1-8b-instant", c.BuildRequestBody(messages).Model)
+	assert.Equal(t, "gemma-7b-it", c.BuildRequestBody(messages, WithModel("gemma-7b-it")).Model)
+	// A per-call model is respected even when it is the one of the profile.
+	assert.Equal(t, "llama3-70b-8192", c.BuildRequestBody(messages, WithModel("llama3-70b-8192")).Model)
+
+	// Options may depend on the messages of the request.
+	lastMessage := func(rb *RequestBody) {
+		rb.MaxTokens = len(rb.Messages[len(rb.Messages)-1].Content)
+	}
+	body := c.BuildRequestBody(messages, lastMessage)
+	assert.Equal(t, "llama-3.1-8b-instant", body.Model)
+	assert.Equal(t, 13, body.MaxTokens)
+}
@@ -0,0 +1,68 @@
+package groq
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordStream yields the content of the first choice of a stream in whole words, for smoother
+// rendering than the raw token fragments, which may split words. Each call to Recv returns
+// the content up to and including the last whitespace received so far; the remainder is
+// returned once the stream ends.
+type WordStream struct {
+	stream  *ChatCompletionStream
+	pending string
+	done    bool
+}
+
+// NewWordStream returns a WordStream reading from the given stream.
+func NewWordStream(stream *ChatCompletionStream) *WordStream {
+	return &WordStream{stream: stream}
+}
+
+// Recv returns the next whole words of the content. It returns io.EOF once the content has
+// been returned completely, and the error of the underlying stream if it fails.
+func (w *WordStream) Recv() (string, error) {
+	for !w.done {
+		chunk, err := w.stream.Recv()
+		if err == io.EOF {
+			w.done = true
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				w.pending += choice.Delta.Content
+			}
+		}
+
+		if i := strings.LastIndexFunc(w.pending, unicode.IsSpace); i >= 0 {
+			_, size := utf8.DecodeRuneInString(w.pending[i:])
+			words := w.pending[:i+size]
+			w.pending = w.pending[i+size:]
+			return words, nil
+		}
+	}
+
+	if w.pending != "" {
+		words := w.pending
+		w.pending = ""
+		return words, nil
+	}
+	return "", io.EOF
+}
+
+// Usage returns the usage statistics of the underlying stream.
+func (w *WordStream) Usage() *Usage {
+	return w.stream.Usage()
+}
+
+// Close closes the underlying stream.
+func (w *WordStream) Close() error {
+	return w.stream.Close()
+}
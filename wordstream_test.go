@@ -0,0 +1,38 @@
+package groq
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordStream(t *testing.T) {
+	c := newTestClient(t, streamHandler(
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"Hel\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"lo wo\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \"rld,\\nhow\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \" are\"}}]}\n\n",
+		"data: {\"id\": \"1\", \"choices\": [{\"index\": 0, \"delta\": {\"content\": \" you?\"}, \"finish_reason\": \"stop\"}]}\n\n",
+		"data: [DONE]\n\n",
+	))
+
+	stream, err := c.ChatCompletionStream(context.Background(), []Message{{Role: "user", Content: "Hello, world!"}})
+	assert.Nil(t, err)
+
+	words := NewWordStream(stream)
+	defer words.Close()
+
+	var received []string
+	for {
+		text, err := words.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		received = append(received, text)
+	}
+
+	assert.Equal(t, []string{"Hello ", "world,\n", "how ", "are ", "you?"}, received)
+}